@@ -1,14 +1,34 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/cpi"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/fx"
 	"github.com/juank/finance-ai/backend/internal/processor"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "reclassify" {
+		reclassify(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "fx-import" {
+		importRates(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cpi-import" {
+		importPriceIndex(os.Args[2:])
+		return
+	}
+
 	fmt.Println("Starting Financial Processor (Go Native)...")
 
 	outputDir := "/Users/juank/Documents/Cuentas/DatosClasificados"
@@ -21,3 +41,92 @@ func main() {
 
 	fmt.Println("Processing completed successfully.")
 }
+
+// reclassify re-runs the classification rules over a user's stored transactions:
+//
+//	processor reclassify -user <uuid> [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-source brubank]
+func reclassify(args []string) {
+	fs := flag.NewFlagSet("reclassify", flag.ExitOnError)
+	user := fs.String("user", "", "ID of the user whose transactions are reclassified")
+	from := fs.String("from", "", "first date to reclassify (YYYY-MM-DD)")
+	to := fs.String("to", "", "last date to reclassify (YYYY-MM-DD)")
+	source := fs.String("source", "", "only reclassify transactions from this source")
+	fs.Parse(args)
+
+	userID, err := uuid.Parse(*user)
+	if err != nil {
+		log.Fatalf("A valid -user ID is required: %v", err)
+	}
+
+	database, err := db.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	db.Instance = database
+
+	report, err := processor.Reclassify(userID, processor.ReclassifyFilter{From: *from, To: *to, Source: *source})
+	if err != nil {
+		log.Fatalf("Error reclassifying transactions: %v", err)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+}
+
+// importRates loads exchange rates from a CSV or JSON file into the database:
+//
+//	processor fx-import -file rates.csv [-type mep]
+func importRates(args []string) {
+	fs := flag.NewFlagSet("fx-import", flag.ExitOnError)
+	file := fs.String("file", "", "CSV (date,base,quote,rate[,rate_type]) or JSON ({\"rates\": [...]}) file")
+	rateType := fs.String("type", fx.RateOfficial, "rate type for rows without one: "+strings.Join(fx.RateTypes, ", "))
+	fs.Parse(args)
+
+	if *file == "" {
+		log.Fatal("A -file is required")
+	}
+	if !fx.ValidRateType(*rateType) {
+		log.Fatalf("Unknown rate type %q", *rateType)
+	}
+	rates, err := fx.ReadFile(*file, *rateType)
+	if err != nil {
+		log.Fatalf("Error reading rates: %v", err)
+	}
+
+	database, err := db.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	if err := database.SaveFXRates(rates); err != nil {
+		log.Fatalf("Error saving rates: %v", err)
+	}
+	fmt.Printf("Imported %d rates from %s\n", len(rates), *file)
+}
+
+// importPriceIndex loads a monthly consumer price index from a CSV or JSON file
+// into the database:
+//
+//	processor cpi-import -file ipc.csv [-currency ARS]
+func importPriceIndex(args []string) {
+	fs := flag.NewFlagSet("cpi-import", flag.ExitOnError)
+	file := fs.String("file", "", "CSV (month,value[,currency]) or JSON ({\"index\": [...]}) file")
+	currency := fs.String("currency", cpi.DefaultCurrency, "currency of values without one")
+	fs.Parse(args)
+
+	if *file == "" {
+		log.Fatal("A -file is required")
+	}
+	values, err := cpi.ReadFile(*file, *currency)
+	if err != nil {
+		log.Fatalf("Error reading price index: %v", err)
+	}
+
+	database, err := db.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	if err := database.SavePriceIndex(values); err != nil {
+		log.Fatalf("Error saving price index: %v", err)
+	}
+	fmt.Printf("Imported %d index values from %s\n", len(values), *file)
+}
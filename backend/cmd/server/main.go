@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,27 +10,41 @@ import (
 	"encoding/json"
 	"io"
 	"path/filepath"
-	"time"
-
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/juank/finance-ai/backend/internal/api"
 	"github.com/juank/finance-ai/backend/internal/auth"
 	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/fx"
 	"github.com/juank/finance-ai/backend/internal/models"
 	"github.com/juank/finance-ai/backend/internal/processor"
 	"github.com/juank/finance-ai/backend/internal/processor/common"
-	"github.com/juank/finance-ai/backend/internal/processor/parsers"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+
 	// Initialize Database
 	database, err := db.Connect()
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if pg, ok := database.(*db.PostgresDB); ok && os.Getenv("DB_AUTO_MIGRATE") != "false" {
+		applied, err := pg.Migrate()
+		for _, m := range applied {
+			log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
 	db.Instance = database
+	fx.SetStore(database)
 
 	mux := http.NewServeMux()
 
@@ -40,11 +55,90 @@ func main() {
 	// Protected routes
 	mux.HandleFunc("/api/upload", api.AuthMiddleware(handleUpload))
 	mux.HandleFunc("/api/transactions", api.AuthMiddleware(handleTransactions))
+	mux.HandleFunc("/api/transactions/", api.AuthMiddleware(api.HandleTransaction))
+	mux.HandleFunc("/api/transactions/reclassify", api.AuthMiddleware(api.HandleReclassify))
+	mux.HandleFunc("/api/parsers", api.AuthMiddleware(api.HandleParsers))
+	mux.HandleFunc("/api/accounts", api.AuthMiddleware(api.HandleAccounts))
+	mux.HandleFunc("/api/accounts/", api.AuthMiddleware(api.HandleAccount))
+	mux.HandleFunc("/api/import-profiles", api.AuthMiddleware(api.HandleImportProfiles))
+	mux.HandleFunc("/api/import-profiles/", api.AuthMiddleware(api.HandleImportProfile))
+	mux.HandleFunc("/api/rules", api.AuthMiddleware(api.HandleRules))
+	mux.HandleFunc("/api/rules/", api.AuthMiddleware(api.HandleRule))
+	mux.HandleFunc("/api/transfer-links", api.AuthMiddleware(api.HandleTransferLinks))
+	mux.HandleFunc("/api/transfer-links/", api.AuthMiddleware(api.HandleTransferLink))
+	mux.HandleFunc("/api/duplicates", api.AuthMiddleware(api.HandleDuplicates))
+	mux.HandleFunc("/api/duplicates/", api.AuthMiddleware(api.HandleDuplicate))
+	mux.HandleFunc("/api/settings/transfers", api.AuthMiddleware(api.HandleTransferSettings))
+	mux.HandleFunc("/api/reports/categories", api.AuthMiddleware(api.HandleCategoryReport))
+	mux.HandleFunc("/api/merchants", api.AuthMiddleware(api.HandleMerchants))
+	mux.HandleFunc("/api/merchant-aliases", api.AuthMiddleware(api.HandleMerchantAliases))
+	mux.HandleFunc("/api/merchant-aliases/", api.AuthMiddleware(api.HandleMerchantAlias))
 
 	fmt.Println("Server starting on :8080...")
 	log.Fatal(http.ListenAndServe(":8080", api.CORSMiddleware(mux)))
 }
 
+// migrate applies or reverts schema migrations, or shows which are applied:
+//
+//	main migrate [up]
+//	main migrate down [-steps 1]
+//	main migrate status
+func migrate(args []string) {
+	command := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("migrate "+command, flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of migrations to revert (down)")
+	fs.Parse(args)
+
+	database, err := db.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	pg, ok := database.(*db.PostgresDB)
+	if !ok {
+		log.Fatal("Migrations need a Postgres database: set DB_HOST")
+	}
+
+	switch command {
+	case "up":
+		applied, err := pg.Migrate()
+		for _, m := range applied {
+			fmt.Printf("Applied %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error migrating database: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		reverted, err := pg.MigrateDown(*steps)
+		for _, m := range reverted {
+			fmt.Printf("Reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error reverting migrations: %v", err)
+		}
+	case "status":
+		states, version, err := pg.MigrationStatus()
+		if err != nil {
+			log.Fatalf("Error reading migrations: %v", err)
+		}
+		fmt.Printf("Schema version: %d\n", version)
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-30s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		log.Fatalf("Unknown migrate command %q: use up, down or status", command)
+	}
+}
+
 func handleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -146,17 +240,44 @@ func handleUpload(w http.ResponseWriter, r *http.Request) {
 	io.Copy(dst, file)
 	dst.Close()
 
-	// Pick Parser
-	parser := pickParser(handler.Filename)
-	if parser == nil {
-		http.Error(w, "Unsupported file type or bank", http.StatusBadRequest)
-		return
+	// Resolve Parser: import profile, forced by the client or detected from content
+	var detected *processor.DetectedParser
+	if profileID := r.FormValue("profile_id"); profileID != "" {
+		id, err := uuid.Parse(profileID)
+		if err != nil {
+			http.Error(w, "Invalid profile id", http.StatusBadRequest)
+			return
+		}
+		profile, err := db.GetDB().GetImportProfile(userID, id)
+		if err != nil {
+			http.Error(w, "Unknown import profile", http.StatusBadRequest)
+			return
+		}
+		detected = processor.ProfileParser(profile)
+	} else {
+		detected, err = processor.ResolveParser(tempPath, r.FormValue("parser"))
+		if err != nil {
+			http.Error(w, "Unsupported file type or bank: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
 	// Trigger processing
 	outputDir := "/Users/juank/Documents/Cuentas/DatosClasificados"
 	engine := processor.NewEngine(outputDir, userID)
-	txs, err := engine.ProcessFile(tempPath, parser, uploadID)
+	if accountID := r.FormValue("account_id"); accountID != "" {
+		id, err := uuid.Parse(accountID)
+		if err != nil {
+			http.Error(w, "Invalid account id", http.StatusBadRequest)
+			return
+		}
+		if _, err := db.GetDB().GetAccount(userID, id); err != nil {
+			http.Error(w, "Unknown account", http.StatusBadRequest)
+			return
+		}
+		engine.AccountID = id
+	}
+	txs, err := engine.ProcessFile(tempPath, detected.Parser, uploadID)
 	if err != nil {
 		http.Error(w, "Processing failed: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -167,37 +288,16 @@ func handleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"message":   "File processed successfully",
 		"upload_id": uploadID,
 		"count":     len(txs),
-	})
-}
-
-func pickParser(filename string) common.Normalizer {
-	ext := strings.ToLower(filepath.Ext(filename))
-	name := strings.ToLower(filename)
-
-	if ext == ".pdf" {
-		if strings.Contains(name, "brubank") {
-			return &parsers.BrubankPDFParser{}
-		}
-		if strings.Contains(name, "visa") || strings.Contains(name, "santander") {
-			return &parsers.SantanderVisaPDFParser{}
-		}
-	} else if ext == ".csv" {
-		if strings.Contains(name, "mercadopago") {
-			return &parsers.MercadoPagoParser{}
-		}
-		if strings.Contains(name, "deel") {
-			return &parsers.DeelParser{}
-		}
-	} else if ext == ".xlsx" {
-		if strings.Contains(name, "santander") {
-			return &parsers.SantanderXLSXParser{}
-		}
+		"parser":    detected,
+	}
+	if reporter, ok := detected.Parser.(common.StatementReporter); ok {
+		resp["statements"] = reporter.Statements()
 	}
-	return nil
+	api.JSONResponse(w, http.StatusOK, resp)
 }
 
 func handleTransactions(w http.ResponseWriter, r *http.Request) {
@@ -208,7 +308,28 @@ func handleTransactions(w http.ResponseWriter, r *http.Request) {
 
 	userIDStr := r.Header.Get("X-User-ID")
 	userID, _ := uuid.Parse(userIDStr)
+	conv, ok := api.ConverterFrom(w, r)
+	if !ok {
+		return
+	}
 
-	txs := db.GetDB().GetTransactions(userID)
+	// Rows merged as duplicates of another transaction are hidden unless asked for
+	needsReview := r.URL.Query().Get("needs_review") == "true"
+	withDuplicates := r.URL.Query().Get("include_duplicates") == "true"
+	accountID := r.URL.Query().Get("account_id")
+	txs := []models.Transaction{}
+	for _, tx := range db.GetDB().GetTransactions(userID) {
+		if (needsReview && !tx.NeedsReview) || (tx.DuplicateOf != nil && !withDuplicates) {
+			continue
+		}
+		if accountID != "" && (tx.AccountID == nil || tx.AccountID.String() != accountID) {
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	if conv != nil {
+		api.JSONResponse(w, http.StatusOK, conv.Transactions(txs))
+		return
+	}
 	api.JSONResponse(w, http.StatusOK, txs)
 }
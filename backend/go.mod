@@ -1,6 +1,6 @@
 module github.com/juank/finance-ai/backend
 
-go 1.20
+go 1.24.1
 
 require (
 	github.com/golang-jwt/jwt/v4 v4.5.0
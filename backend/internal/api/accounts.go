@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor"
+)
+
+var accountTypes = map[string]bool{
+	models.AccountSavings:    true,
+	models.AccountChecking:   true,
+	models.AccountCreditCard: true,
+	models.AccountWallet:     true,
+}
+
+// HandleAccounts lists (GET) and creates (POST) the user's accounts
+func HandleAccounts(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		JSONResponse(w, http.StatusOK, db.GetDB().GetAccounts(userID))
+	case http.MethodPost:
+		account, ok := decodeAccount(w, r)
+		if !ok {
+			return
+		}
+		account.ID = uuid.New()
+		account.UserID = userID
+		account.CreatedAt = time.Now()
+		if account.ParserAccount == "" {
+			account.ParserAccount = account.ID.String()
+		}
+		if err := db.GetDB().CreateAccount(account); err != nil {
+			writeAccountError(w, err)
+			return
+		}
+		JSONResponse(w, http.StatusCreated, account)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleAccount reads (GET), replaces (PUT) or deletes (DELETE) /api/accounts/{id}.
+// Accounts that transactions belong to cannot be deleted.
+func HandleAccount(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+	id, err := PathID(r, "/api/accounts/")
+	if err != nil {
+		http.Error(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		account, err := db.GetDB().GetAccount(userID, id)
+		if err != nil {
+			writeDBError(w, err, "Account not found")
+			return
+		}
+		JSONResponse(w, http.StatusOK, account)
+	case http.MethodPut:
+		account, ok := decodeAccount(w, r)
+		if !ok {
+			return
+		}
+		stored, err := db.GetDB().GetAccount(userID, id)
+		if err != nil {
+			writeDBError(w, err, "Account not found")
+			return
+		}
+		account.ID = id
+		account.UserID = userID
+		account.CreatedAt = stored.CreatedAt
+		if account.ParserAccount == "" {
+			account.ParserAccount = stored.ParserAccount
+		}
+		if err := db.GetDB().UpdateAccount(account); err != nil {
+			writeAccountError(w, err)
+			return
+		}
+		JSONResponse(w, http.StatusOK, account)
+	case http.MethodDelete:
+		if err := db.GetDB().DeleteAccount(userID, id); err != nil {
+			if err == db.ErrConflict {
+				http.Error(w, "Account has transactions", http.StatusConflict)
+				return
+			}
+			writeDBError(w, err, "Account not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func decodeAccount(w http.ResponseWriter, r *http.Request) (models.Account, bool) {
+	var account models.Account
+	if err := json.NewDecoder(r.Body).Decode(&account); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return account, false
+	}
+	account.Name = strings.TrimSpace(account.Name)
+	account.Institution = strings.ToLower(strings.TrimSpace(account.Institution))
+	account.ParserAccount = strings.TrimSpace(account.ParserAccount)
+	account.Currency = strings.ToUpper(strings.TrimSpace(account.Currency))
+
+	switch {
+	case account.Name == "" || account.Institution == "":
+		http.Error(w, "name and institution are required", http.StatusBadRequest)
+		return account, false
+	case !accountTypes[account.Type]:
+		http.Error(w, "type must be savings, checking, credit_card or wallet", http.StatusBadRequest)
+		return account, false
+	case !currencyCodeRegex.MatchString(account.Currency):
+		http.Error(w, "currency must be a 3-letter currency code", http.StatusBadRequest)
+		return account, false
+	}
+
+	// Only the last digits of the number are kept
+	if account.Number != nil && strings.TrimSpace(*account.Number) == "" {
+		account.Number = nil
+	}
+	if account.Number != nil {
+		masked := processor.MaskAccountNumber(*account.Number)
+		if masked == "" {
+			http.Error(w, "number must have at least 4 digits", http.StatusBadRequest)
+			return account, false
+		}
+		account.Number = &masked
+	}
+	return account, true
+}
+
+// writeAccountError maps a clash with another account to 409
+func writeAccountError(w http.ResponseWriter, err error) {
+	if err == db.ErrConflict {
+		http.Error(w, "Another account has this institution and parser_account", http.StatusConflict)
+		return
+	}
+	writeDBError(w, err, "Account not found")
+}
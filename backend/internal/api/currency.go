@@ -0,0 +1,155 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/juank/finance-ai/backend/internal/cpi"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/fx"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Converter converts amounts into the base currency asked for with
+// ?base=USD&rate_type=mep, using the rate of each transaction's date
+type Converter struct {
+	Base     string
+	RateType string
+	rates    *fx.Table
+}
+
+// ConvertedTransaction is a transaction with its amount in the base currency.
+// Converted is null when no rate was found for its currency and date.
+type ConvertedTransaction struct {
+	models.Transaction
+	Converted *fx.Conversion `json:"converted"`
+}
+
+// ConvertedTotal is a sum of amounts in the base currency. Missing counts the
+// transactions left out because no rate was found.
+type ConvertedTotal struct {
+	money.Money
+	RateType string `json:"rate_type"`
+	Missing  int    `json:"missing_rates"`
+}
+
+// ConverterFrom reads ?base= and ?rate_type= (official by default). It returns
+// nil when no base currency was asked for, and writes a 400 on invalid values.
+func ConverterFrom(w http.ResponseWriter, r *http.Request) (*Converter, bool) {
+	q := r.URL.Query()
+	base := strings.ToUpper(strings.TrimSpace(q.Get("base")))
+	if base == "" {
+		if q.Get("rate_type") != "" {
+			http.Error(w, "rate_type requires base", http.StatusBadRequest)
+			return nil, false
+		}
+		return nil, true
+	}
+	if !currencyCodeRegex.MatchString(base) {
+		http.Error(w, "base must be a 3-letter currency code", http.StatusBadRequest)
+		return nil, false
+	}
+	rateType := strings.ToLower(q.Get("rate_type"))
+	if rateType == "" {
+		rateType = fx.RateOfficial
+	}
+	if !fx.ValidRateType(rateType) {
+		http.Error(w, fmt.Sprintf("rate_type must be one of %s", strings.Join(fx.RateTypes, ", ")), http.StatusBadRequest)
+		return nil, false
+	}
+	return &Converter{Base: base, RateType: rateType, rates: fx.Default()}, true
+}
+
+// Convert returns the amount of tx in the base currency
+func (c *Converter) Convert(tx models.Transaction) (fx.Conversion, bool) {
+	return c.rates.Convert(tx.Money(), tx.Date, c.Base, c.RateType)
+}
+
+// Transactions pairs each transaction with its converted amount
+func (c *Converter) Transactions(txs []models.Transaction) []ConvertedTransaction {
+	result := make([]ConvertedTransaction, 0, len(txs))
+	for _, tx := range txs {
+		row := ConvertedTransaction{Transaction: tx}
+		if conv, ok := c.Convert(tx); ok {
+			row.Converted = &conv
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+// NewTotal starts an empty sum in the base currency
+func (c *Converter) NewTotal() *ConvertedTotal {
+	return &ConvertedTotal{Money: money.New(0, c.Base), RateType: c.RateType}
+}
+
+// Add converts tx and adds it to total
+func (c *Converter) Add(total *ConvertedTotal, tx models.Transaction) {
+	conv, ok := c.Convert(tx)
+	if !ok {
+		total.Missing++
+		return
+	}
+	sum, err := total.Money.Add(conv.Money)
+	if err != nil {
+		total.Missing++
+		return
+	}
+	total.Money = sum
+}
+
+// Deflator expresses amounts in constant prices of the reference month asked
+// for with ?real=YYYY-MM, using the stored monthly price index
+type Deflator struct {
+	Month string
+	index *cpi.Table
+}
+
+// RealTotals are sums in constant prices of ReferenceMonth, for the currencies
+// that have a price index. Missing counts the transactions left out because
+// their currency has no index value for their month or the reference month.
+type RealTotals struct {
+	ReferenceMonth string       `json:"reference_month"`
+	Totals         money.Totals `json:"totals"`
+	Missing        int          `json:"missing_index"`
+}
+
+// DeflatorFrom reads ?real=. It returns nil when no reference month was asked
+// for, and writes a 400 when the month is invalid or has no index value.
+func DeflatorFrom(w http.ResponseWriter, r *http.Request) (*Deflator, bool) {
+	month := strings.TrimSpace(r.URL.Query().Get("real"))
+	if month == "" {
+		return nil, true
+	}
+	if !cpi.ValidMonth(month) {
+		http.Error(w, "real must be a YYYY-MM month", http.StatusBadRequest)
+		return nil, false
+	}
+	index := cpi.NewTable(db.GetDB().GetPriceIndex())
+	if !index.HasMonth(month) {
+		http.Error(w, "No price index for "+month, http.StatusBadRequest)
+		return nil, false
+	}
+	return &Deflator{Month: month, index: index}, true
+}
+
+// NewTotals starts empty sums in constant prices
+func (d *Deflator) NewTotals() *RealTotals {
+	return &RealTotals{ReferenceMonth: d.Month, Totals: make(money.Totals)}
+}
+
+// Add adjusts tx and adds it to totals, or counts it as missing when it
+// cannot be adjusted
+func (d *Deflator) Add(totals *RealTotals, tx models.Transaction) {
+	amount, ok := d.index.Adjust(tx.Amount, tx.Currency, tx.Date, d.Month)
+	if !ok {
+		totals.Missing++
+		return
+	}
+	totals.Totals.Add(money.New(amount, tx.Currency))
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor"
+)
+
+// DuplicateReview is a duplicate candidate with both transactions, for review
+type DuplicateReview struct {
+	models.DuplicateCandidate
+	Transaction *models.Transaction `json:"transaction,omitempty"`
+	Duplicate   *models.Transaction `json:"duplicate,omitempty"`
+}
+
+// HandleDuplicates lists (GET) the user's duplicate candidates. Only pending
+// ones are listed unless ?status=merged, dismissed or all is given.
+func HandleDuplicates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := UserID(r)
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.DuplicatePending
+	}
+
+	reviews := []DuplicateReview{}
+	for _, c := range db.GetDB().GetDuplicateCandidates(userID) {
+		if status != "all" && c.Status != status {
+			continue
+		}
+		review := DuplicateReview{DuplicateCandidate: c}
+		if tx, err := db.GetDB().GetTransaction(userID, c.TransactionID); err == nil {
+			review.Transaction = &tx
+		}
+		if tx, err := db.GetDB().GetTransaction(userID, c.DuplicateID); err == nil {
+			review.Duplicate = &tx
+		}
+		reviews = append(reviews, review)
+	}
+	JSONResponse(w, http.StatusOK, reviews)
+}
+
+// HandleDuplicate resolves (PATCH {"status": "merged" | "dismissed"})
+// /api/duplicates/{id}
+func HandleDuplicate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := PathID(r, "/api/duplicates/")
+	if err != nil {
+		http.Error(w, "Invalid duplicate id", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+		(req.Status != models.DuplicateMerged && req.Status != models.DuplicateDismissed) {
+		http.Error(w, `status must be "merged" or "dismissed"`, http.StatusBadRequest)
+		return
+	}
+
+	c, err := processor.ResolveDuplicate(UserID(r), id, req.Status)
+	if err != nil {
+		writeDBError(w, err, "Duplicate not found")
+		return
+	}
+	JSONResponse(w, http.StatusOK, c)
+}
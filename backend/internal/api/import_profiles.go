@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/parsers"
+)
+
+// HandleImportProfiles lists (GET) and creates (POST) the user's CSV import profiles
+func HandleImportProfiles(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		JSONResponse(w, http.StatusOK, db.GetDB().GetImportProfiles(userID))
+	case http.MethodPost:
+		var profile models.ImportProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		if err := parsers.ValidateProfile(profile); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		profile.ID = uuid.New()
+		profile.UserID = userID
+		profile.CreatedAt = time.Now()
+		if err := db.GetDB().CreateImportProfile(profile); err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		JSONResponse(w, http.StatusCreated, profile)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleImportProfile reads (GET), replaces (PUT) or deletes (DELETE) /api/import-profiles/{id}
+func HandleImportProfile(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+	id, err := PathID(r, "/api/import-profiles/")
+	if err != nil {
+		http.Error(w, "Invalid profile id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := db.GetDB().GetImportProfile(userID, id)
+		if err != nil {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		}
+		JSONResponse(w, http.StatusOK, profile)
+	case http.MethodPut:
+		var profile models.ImportProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		if err := parsers.ValidateProfile(profile); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		profile.ID = id
+		profile.UserID = userID
+		if err := db.GetDB().UpdateImportProfile(profile); err != nil {
+			writeDBError(w, err, "Profile not found")
+			return
+		}
+		profile, _ = db.GetDB().GetImportProfile(userID, id)
+		JSONResponse(w, http.StatusOK, profile)
+	case http.MethodDelete:
+		if err := db.GetDB().DeleteImportProfile(userID, id); err != nil {
+			writeDBError(w, err, "Profile not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor"
+)
+
+// MerchantSummary groups a user's transactions by canonical merchant
+type MerchantSummary struct {
+	Merchant    string          `json:"merchant"`
+	RawVariants []string        `json:"raw_variants"`
+	Count       int             `json:"count"`
+	Totals      money.Totals    `json:"totals"` // amount per currency
+	Converted   *ConvertedTotal `json:"converted,omitempty"`
+	Real        *RealTotals     `json:"real,omitempty"`
+}
+
+// HandleMerchants lists the user's canonical merchants with the raw variants
+// that were collapsed into each of them, ordered by number of transactions.
+// With ?base= the totals are also converted into that currency, and with
+// ?real= expressed in constant prices of that month.
+func HandleMerchants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	conv, ok := ConverterFrom(w, r)
+	if !ok {
+		return
+	}
+	deflator, ok := DeflatorFrom(w, r)
+	if !ok {
+		return
+	}
+
+	groups := make(map[string]*MerchantSummary)
+	variants := make(map[string]map[string]bool)
+	for _, tx := range db.GetDB().GetTransactions(UserID(r)) {
+		if tx.Merchant == nil || *tx.Merchant == "" || tx.Neutralized || tx.DuplicateOf != nil {
+			continue
+		}
+		name := *tx.Merchant
+		g, ok := groups[name]
+		if !ok {
+			g = &MerchantSummary{Merchant: name, RawVariants: []string{}, Totals: make(money.Totals)}
+			if conv != nil {
+				g.Converted = conv.NewTotal()
+			}
+			if deflator != nil {
+				g.Real = deflator.NewTotals()
+			}
+			groups[name] = g
+			variants[name] = make(map[string]bool)
+		}
+		g.Count++
+		g.Totals.Add(tx.Money())
+		if conv != nil {
+			conv.Add(g.Converted, tx)
+		}
+		if deflator != nil {
+			deflator.Add(g.Real, tx)
+		}
+		if tx.RawMerchant != nil && *tx.RawMerchant != name && !variants[name][*tx.RawMerchant] {
+			variants[name][*tx.RawMerchant] = true
+			g.RawVariants = append(g.RawVariants, *tx.RawMerchant)
+		}
+	}
+
+	result := make([]MerchantSummary, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.RawVariants)
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Merchant < result[j].Merchant
+	})
+	JSONResponse(w, http.StatusOK, result)
+}
+
+// HandleMerchantAliases lists (GET) and creates (POST) the user's merchant aliases
+func HandleMerchantAliases(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		JSONResponse(w, http.StatusOK, db.GetDB().GetMerchantAliases(userID))
+	case http.MethodPost:
+		alias, ok := decodeMerchantAlias(w, r)
+		if !ok {
+			return
+		}
+		alias.ID = uuid.New()
+		alias.UserID = userID
+		alias.CreatedAt = time.Now()
+		if err := db.GetDB().CreateMerchantAlias(alias); err != nil {
+			writeAliasError(w, err)
+			return
+		}
+		reapplyAliases(userID)
+		JSONResponse(w, http.StatusCreated, alias)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleMerchantAlias replaces (PUT) or deletes (DELETE) /api/merchant-aliases/{id}
+func HandleMerchantAlias(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+	id, err := PathID(r, "/api/merchant-aliases/")
+	if err != nil {
+		http.Error(w, "Invalid alias id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		alias, ok := decodeMerchantAlias(w, r)
+		if !ok {
+			return
+		}
+		alias.ID = id
+		alias.UserID = userID
+		if err := db.GetDB().UpdateMerchantAlias(alias); err != nil {
+			writeAliasError(w, err)
+			return
+		}
+		reapplyAliases(userID)
+		JSONResponse(w, http.StatusOK, alias)
+	case http.MethodDelete:
+		if err := db.GetDB().DeleteMerchantAlias(userID, id); err != nil {
+			writeDBError(w, err, "Alias not found")
+			return
+		}
+		reapplyAliases(userID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeAliasError maps a clash with another alias of the user to 409
+func writeAliasError(w http.ResponseWriter, err error) {
+	if err == db.ErrConflict {
+		http.Error(w, "Another alias has this text", http.StatusConflict)
+		return
+	}
+	writeDBError(w, err, "Alias not found")
+}
+
+func decodeMerchantAlias(w http.ResponseWriter, r *http.Request) (models.MerchantAlias, bool) {
+	var alias models.MerchantAlias
+	if err := json.NewDecoder(r.Body).Decode(&alias); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return alias, false
+	}
+	alias.Alias = strings.TrimSpace(alias.Alias)
+	alias.Canonical = strings.TrimSpace(alias.Canonical)
+	if alias.Alias == "" || alias.Canonical == "" {
+		http.Error(w, "alias and canonical are required", http.StatusBadRequest)
+		return alias, false
+	}
+	return alias, true
+}
+
+// reapplyAliases brings stored transactions in line with the changed alias table
+func reapplyAliases(userID uuid.UUID) {
+	if _, err := processor.ApplyMerchantAliases(userID); err != nil {
+		log.Printf("merchant aliases: %v", err)
+	}
+}
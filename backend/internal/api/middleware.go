@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/juank/finance-ai/backend/internal/auth"
+	"github.com/juank/finance-ai/backend/internal/db"
 )
 
 func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
@@ -37,7 +39,7 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 
 		if r.Method == "OPTIONS" {
@@ -54,3 +56,23 @@ func JSONResponse(w http.ResponseWriter, status int, data interface{}) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
+
+// writeDBError maps db.ErrNotFound to 404 and anything else to 500
+func writeDBError(w http.ResponseWriter, err error, notFound string) {
+	if err == db.ErrNotFound {
+		http.Error(w, notFound, http.StatusNotFound)
+		return
+	}
+	http.Error(w, "Internal error", http.StatusInternalServerError)
+}
+
+// UserID returns the authenticated user set by AuthMiddleware
+func UserID(r *http.Request) uuid.UUID {
+	userID, _ := uuid.Parse(r.Header.Get("X-User-ID"))
+	return userID
+}
+
+// PathID parses the UUID that follows prefix in the request path
+func PathID(r *http.Request, prefix string) (uuid.UUID, error) {
+	return uuid.Parse(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"))
+}
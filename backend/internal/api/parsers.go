@@ -0,0 +1,18 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/juank/finance-ai/backend/internal/processor"
+)
+
+// HandleParsers lists the registered statement parsers so clients can show
+// supported banks and force a specific parser on upload
+func HandleParsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	JSONResponse(w, http.StatusOK, processor.Parsers())
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/juank/finance-ai/backend/internal/cpi"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// CategoryMonth totals a user's spending and income in one category and month
+type CategoryMonth struct {
+	Month     string          `json:"month"` // YYYY-MM
+	Category  *string         `json:"category"`
+	Count     int             `json:"count"`
+	Totals    money.Totals    `json:"totals"` // amount per currency
+	Converted *ConvertedTotal `json:"converted,omitempty"`
+	Real      *RealTotals     `json:"real,omitempty"`
+}
+
+// HandleCategoryReport totals the user's transactions by month and category,
+// oldest month first. ?from= and ?to= (YYYY-MM) bound the months. With ?base=
+// the totals are also converted into that currency, and with ?real= expressed
+// in constant prices of that month. Neutralized transfers and merged
+// duplicates are left out.
+func HandleCategoryReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if (from != "" && !cpi.ValidMonth(from)) || (to != "" && !cpi.ValidMonth(to)) {
+		http.Error(w, "from and to must be YYYY-MM months", http.StatusBadRequest)
+		return
+	}
+	conv, ok := ConverterFrom(w, r)
+	if !ok {
+		return
+	}
+	deflator, ok := DeflatorFrom(w, r)
+	if !ok {
+		return
+	}
+
+	type key struct{ month, category string }
+	rows := make(map[key]*CategoryMonth)
+	for _, tx := range db.GetDB().GetTransactions(UserID(r)) {
+		if tx.Neutralized || tx.DuplicateOf != nil || len(tx.Date) < 7 {
+			continue
+		}
+		month := tx.Date[:7]
+		if (from != "" && month < from) || (to != "" && month > to) {
+			continue
+		}
+		k := key{month: month}
+		if tx.Category != nil {
+			k.category = *tx.Category
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &CategoryMonth{Month: month, Category: tx.Category, Totals: make(money.Totals)}
+			if conv != nil {
+				row.Converted = conv.NewTotal()
+			}
+			if deflator != nil {
+				row.Real = deflator.NewTotals()
+			}
+			rows[k] = row
+		}
+		row.Count++
+		row.Totals.Add(tx.Money())
+		if conv != nil {
+			conv.Add(row.Converted, tx)
+		}
+		if deflator != nil {
+			deflator.Add(row.Real, tx)
+		}
+	}
+
+	result := make([]CategoryMonth, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Month != result[j].Month {
+			return result[i].Month < result[j].Month
+		}
+		return categoryName(result[i]) < categoryName(result[j])
+	})
+	JSONResponse(w, http.StatusOK, result)
+}
+
+func categoryName(row CategoryMonth) string {
+	if row.Category == nil {
+		return ""
+	}
+	return *row.Category
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
+)
+
+// HandleRules lists (GET) and creates (POST) the user's classification rules.
+// New rules are appended after the user's existing rules; use reorder to move
+// them.
+func HandleRules(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		JSONResponse(w, http.StatusOK, db.GetDB().GetClassificationRules(userID))
+	case http.MethodPost:
+		rule, _, ok := decodeRule(w, r)
+		if !ok {
+			return
+		}
+		rule.ID = uuid.New()
+		rule.UserID = userID
+		placeRule(db.GetDB().GetClassificationRules(userID), &rule, nil)
+		if err := db.GetDB().CreateClassificationRule(rule); err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		JSONResponse(w, http.StatusCreated, rule)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleRule serves /api/rules/{id} (PUT, DELETE), /api/rules/reorder and /api/rules/preview
+func HandleRule(w http.ResponseWriter, r *http.Request) {
+	switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/rules/"), "/") {
+	case "reorder":
+		handleReorderRules(w, r)
+		return
+	case "preview":
+		handlePreviewRule(w, r)
+		return
+	}
+
+	userID := UserID(r)
+	id, err := PathID(r, "/api/rules/")
+	if err != nil {
+		http.Error(w, "Invalid rule id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		rule, priority, ok := decodeRule(w, r)
+		if !ok {
+			return
+		}
+		rule.ID = id
+		rule.UserID = userID
+		if !placeRule(db.GetDB().GetClassificationRules(userID), &rule, priority) {
+			http.Error(w, "Rule not found", http.StatusNotFound)
+			return
+		}
+		if err := db.GetDB().UpdateClassificationRule(rule); err != nil {
+			writeDBError(w, err, "Rule not found")
+			return
+		}
+		JSONResponse(w, http.StatusOK, rule)
+	case http.MethodDelete:
+		if err := db.GetDB().DeleteClassificationRule(userID, id); err != nil {
+			writeDBError(w, err, "Rule not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleReorderRules sets rule priorities from the order of {"ids": [...]}
+func handleReorderRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		IDs []uuid.UUID `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID := UserID(r)
+	if err := db.GetDB().ReorderClassificationRules(userID, req.IDs); err != nil {
+		writeDBError(w, err, "Rule not found")
+		return
+	}
+	JSONResponse(w, http.StatusOK, db.GetDB().GetClassificationRules(userID))
+}
+
+// handlePreviewRule dry-runs a draft rule against the user's stored transactions
+func handlePreviewRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	draft, priority, ok := decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	userID := UserID(r)
+	draft.UserID = userID
+	rules := db.GetDB().GetClassificationRules(userID)
+	placeRule(rules, &draft, priority)
+	result, err := classifier.Preview(rules, draft, db.GetDB().GetTransactions(userID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	JSONResponse(w, http.StatusOK, result)
+}
+
+// placeRule gives rule the priority and created_at it has once saved: a stored
+// rule keeps both, or takes priority when the request sets one, and a new rule
+// goes after the user's last rule. It reports whether rule was already stored.
+func placeRule(rules []models.ClassificationRule, rule *models.ClassificationRule, priority *int) bool {
+	for _, stored := range rules {
+		if stored.ID == rule.ID {
+			rule.Priority = stored.Priority
+			if priority != nil {
+				rule.Priority = *priority
+			}
+			rule.CreatedAt = stored.CreatedAt
+			return true
+		}
+	}
+	rule.Priority = 0
+	if n := len(rules); n > 0 {
+		rule.Priority = rules[n-1].Priority + 1
+	}
+	rule.CreatedAt = time.Now()
+	return false
+}
+
+// decodeRule reads a rule from the body, and its priority apart because an
+// omitted priority keeps the stored one rather than meaning 0
+func decodeRule(w http.ResponseWriter, r *http.Request) (models.ClassificationRule, *int, bool) {
+	var req struct {
+		models.ClassificationRule
+		Priority *int `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req.ClassificationRule, nil, false
+	}
+	if err := classifier.Validate(req.ClassificationRule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req.ClassificationRule, nil, false
+	}
+	return req.ClassificationRule, req.Priority, true
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
+)
+
+// HandleTransaction serves /api/transactions/{id} and /api/transactions/{id}/explain.
+// PATCH edits the category, subcategory, merchant and notes; only the fields
+// present in the body change (null clears them) and every edited field except
+// notes is locked so later imports and reclassification keep the user's value.
+func HandleTransaction(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/"), "/")
+	if parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "explain") {
+		http.NotFound(w, r)
+		return
+	}
+
+	userID := UserID(r)
+	tx, err := db.GetDB().GetTransaction(userID, parts[0])
+	if err != nil {
+		writeDBError(w, err, "Transaction not found")
+		return
+	}
+
+	if len(parts) == 2 {
+		explainTransaction(w, r, tx)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		JSONResponse(w, http.StatusOK, tx)
+	case http.MethodPatch:
+		var patch map[string]*string
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		for field, value := range patch {
+			if value != nil {
+				v := strings.TrimSpace(*value)
+				value = &v
+				if v == "" {
+					value = nil
+				}
+			}
+			switch field {
+			case "category":
+				tx.Category, tx.CategoryLocked, tx.NeedsReview = value, true, false
+				tx.Provenance = classifier.Provenance(classifier.StageUser, "")
+			case "subcategory":
+				tx.Subcategory, tx.SubcategoryLocked = value, true
+				tx.Provenance = classifier.Provenance(classifier.StageUser, "")
+			case "merchant":
+				tx.Merchant, tx.MerchantLocked = value, true
+			case "notes":
+				tx.Notes = value
+			default:
+				http.Error(w, "Field cannot be edited: "+field, http.StatusBadRequest)
+				return
+			}
+		}
+		if err := db.GetDB().UpdateTransaction(tx); err != nil {
+			writeDBError(w, err, "Transaction not found")
+			return
+		}
+		JSONResponse(w, http.StatusOK, tx)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Explanation describes how a transaction got its category and what the
+// current rules would assign to it now
+type Explanation struct {
+	TransactionID        string                           `json:"transaction_id"`
+	Category             *string                          `json:"category"`
+	Subcategory          *string                          `json:"subcategory"`
+	Provenance           *models.ClassificationProvenance `json:"provenance"`
+	CategoryLocked       bool                             `json:"category_locked"`
+	SubcategoryLocked    bool                             `json:"subcategory_locked"`
+	NeedsReview          bool                             `json:"needs_review"`
+	SuggestedCategory    *string                          `json:"suggested_category,omitempty"`
+	SuggestedSubcategory *string                          `json:"suggested_subcategory,omitempty"`
+	CurrentMatch         *models.ClassificationProvenance `json:"current_match"`
+}
+
+func explainTransaction(w http.ResponseWriter, r *http.Request, tx models.Transaction) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rules, err := classifier.ForUser(tx.UserID)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	exp := Explanation{
+		TransactionID:        tx.ID,
+		Category:             tx.Category,
+		Subcategory:          tx.Subcategory,
+		Provenance:           tx.Provenance,
+		CategoryLocked:       tx.CategoryLocked,
+		SubcategoryLocked:    tx.SubcategoryLocked,
+		NeedsReview:          tx.NeedsReview,
+		SuggestedCategory:    tx.SuggestedCategory,
+		SuggestedSubcategory: tx.SuggestedSubcategory,
+	}
+	if m := rules.Match(&tx); m != nil {
+		exp.CurrentMatch = m.Provenance()
+	}
+	JSONResponse(w, http.StatusOK, exp)
+}
+
+// HandleReclassify re-runs classification over the user's stored transactions.
+// The optional body {"from": "YYYY-MM-DD", "to": "YYYY-MM-DD", "source": "..."}
+// restricts which transactions are considered.
+func HandleReclassify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var filter processor.ReclassifyFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil && err != io.EOF {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	for _, d := range []string{filter.From, filter.To} {
+		if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+			http.Error(w, "Dates must use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := processor.Reclassify(UserID(r), filter)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, http.StatusOK, report)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor"
+)
+
+// HandleTransferLinks lists (GET) the user's transfer links and links two
+// transactions manually (POST {"debit_id": "...", "credit_id": "..."}).
+// Rejected links are only listed with ?include_rejected=true.
+func HandleTransferLinks(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		includeRejected := r.URL.Query().Get("include_rejected") == "true"
+		links := []models.TransferLink{}
+		for _, l := range db.GetDB().GetTransferLinks(userID) {
+			if includeRejected || l.Method != models.LinkRejected {
+				links = append(links, l)
+			}
+		}
+		JSONResponse(w, http.StatusOK, links)
+	case http.MethodPost:
+		var req struct {
+			DebitID  string `json:"debit_id"`
+			CreditID string `json:"credit_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DebitID == "" || req.CreditID == "" {
+			http.Error(w, "debit_id and credit_id are required", http.StatusBadRequest)
+			return
+		}
+		link, err := processor.LinkTransfer(userID, req.DebitID, req.CreditID)
+		switch err {
+		case nil:
+			JSONResponse(w, http.StatusCreated, link)
+		case processor.ErrInvalidPair:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case processor.ErrAlreadyLinked:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			writeDBError(w, err, "Transaction not found")
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleTransferLink removes (DELETE) /api/transfer-links/{id}
+func HandleTransferLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := PathID(r, "/api/transfer-links/")
+	if err != nil {
+		http.Error(w, "Invalid link id", http.StatusBadRequest)
+		return
+	}
+	if err := processor.UnlinkTransfer(UserID(r), id); err != nil {
+		writeDBError(w, err, "Link not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleTransferSettings reads (GET) and replaces (PUT) the user's transfer
+// matching settings. GET returns the options in effect, defaults included.
+func HandleTransferSettings(w http.ResponseWriter, r *http.Request) {
+	userID := UserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		JSONResponse(w, http.StatusOK, effectiveSettings(userID))
+	case http.MethodPut:
+		var s models.TransferSettings
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if msg := validateTransferSettings(s); msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+		s.UserID = userID
+		s.UpdatedAt = time.Now()
+		if err := db.GetDB().SaveTransferSettings(s); err != nil {
+			writeDBError(w, err, "Settings not found")
+			return
+		}
+		JSONResponse(w, http.StatusOK, effectiveSettings(userID))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func effectiveSettings(userID uuid.UUID) models.TransferSettings {
+	opts := processor.MatchOptionsFor(userID)
+	s := models.TransferSettings{
+		AmountTolerance: &opts.AmountTolerance,
+		FXSpread:        &opts.FXSpread,
+		DaysBefore:      &opts.DaysBefore,
+		DaysAfter:       &opts.DaysAfter,
+	}
+	if stored, err := db.GetDB().GetTransferSettings(userID); err == nil {
+		s.UpdatedAt = stored.UpdatedAt
+	}
+	return s
+}
+
+// validateTransferSettings keeps tolerances within 20% and windows within a month
+func validateTransferSettings(s models.TransferSettings) string {
+	for _, v := range []*float64{s.AmountTolerance, s.FXSpread} {
+		if v != nil && (*v < 0 || *v > 0.2) {
+			return "amount_tolerance and fx_spread must be between 0 and 0.2"
+		}
+	}
+	for _, v := range []*int{s.DaysBefore, s.DaysAfter} {
+		if v != nil && (*v < 0 || *v > 31) {
+			return "days_before and days_after must be between 0 and 31"
+		}
+	}
+	return ""
+}
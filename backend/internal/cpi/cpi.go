@@ -0,0 +1,170 @@
+package cpi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// DefaultCurrency is the currency of series imported without one (INDEC IPC)
+const DefaultCurrency = "ARS"
+
+// Index is the value of a monthly consumer price index for prices in Currency
+type Index struct {
+	Currency string  `json:"currency"`
+	Month    string  `json:"month"` // YYYY-MM
+	Value    float64 `json:"value"`
+}
+
+// Table holds monthly index values by currency
+type Table struct {
+	byCurrency map[string]map[string]float64 // CURRENCY -> YYYY-MM -> value
+}
+
+type indexFile struct {
+	Index []Index `json:"index"`
+}
+
+// NewTable indexes values; non-positive values are ignored and a repeated month
+// keeps the last value
+func NewTable(values []Index) *Table {
+	t := &Table{byCurrency: make(map[string]map[string]float64)}
+	for _, v := range values {
+		if v.Value <= 0 {
+			continue
+		}
+		currency := strings.ToUpper(v.Currency)
+		if t.byCurrency[currency] == nil {
+			t.byCurrency[currency] = make(map[string]float64)
+		}
+		t.byCurrency[currency][v.Month] = v.Value
+	}
+	return t
+}
+
+// HasMonth reports whether any currency has a value for month
+func (t *Table) HasMonth(month string) bool {
+	for _, series := range t.byCurrency {
+		if _, ok := series[month]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Adjust expresses amount, in currency on date, in constant prices of the
+// reference month: amount × index(reference) / index(month of date). It fails
+// when either month has no index value.
+func (t *Table) Adjust(amount money.Amount, currency, date, reference string) (money.Amount, bool) {
+	series := t.byCurrency[strings.ToUpper(currency)]
+	if len(date) < 7 {
+		return 0, false
+	}
+	from, ok1 := series[date[:7]]
+	to, ok2 := series[reference]
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return amount.Mul(to / from), true
+}
+
+// ValidMonth reports whether month is a YYYY-MM month
+func ValidMonth(month string) bool {
+	_, err := time.Parse("2006-01", month)
+	return err == nil && len(month) == 7
+}
+
+// ReadFile reads index values from a JSON file ({"index": [...]}) or, for .csv
+// files, a CSV with month and value columns and an optional currency column.
+// Months may be written as YYYY-MM or YYYY-MM-DD. Values without a currency
+// get currency, or ARS when it is empty.
+func ReadFile(path, currency string) ([]Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []Index
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		values, err = readCSV(f)
+	} else {
+		var file indexFile
+		err = json.NewDecoder(f).Decode(&file)
+		values = file.Index
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	for i := range values {
+		v := &values[i]
+		if v.Currency == "" {
+			v.Currency = currency
+		}
+		v.Currency = strings.ToUpper(v.Currency)
+		if len(v.Month) == len("2006-01-02") {
+			v.Month = v.Month[:7]
+		}
+		if !ValidMonth(v.Month) {
+			return nil, fmt.Errorf("%s: value %d: invalid month %q", path, i+1, v.Month)
+		}
+		if len(v.Currency) != 3 {
+			return nil, fmt.Errorf("%s: value %d: invalid currency %q", path, i+1, v.Currency)
+		}
+		if v.Value <= 0 {
+			return nil, fmt.Errorf("%s: value %d: index must be positive", path, i+1)
+		}
+	}
+	return values, nil
+}
+
+func readCSV(r io.Reader) ([]Index, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := make(map[string]int)
+	for i, h := range records[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range []string{"month", "value"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var values []Index
+	for n, row := range records[1:] {
+		value, err := strconv.ParseFloat(cell(row, "value"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value %q", n+2, cell(row, "value"))
+		}
+		values = append(values, Index{Currency: cell(row, "currency"), Month: cell(row, "month"), Value: value})
+	}
+	return values, nil
+}
@@ -0,0 +1,129 @@
+package cpi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+func TestAdjust(t *testing.T) {
+	table := NewTable([]Index{
+		{Currency: "ars", Month: "2024-01", Value: 100},
+		{Currency: "ARS", Month: "2024-02", Value: 80},
+		{Currency: "ARS", Month: "2024-03", Value: 250},
+		{Currency: "USD", Month: "2024-03", Value: 310},
+		{Currency: "ARS", Month: "2024-04", Value: 0},
+	})
+
+	tests := []struct {
+		amount    money.Amount
+		currency  string
+		date, ref string
+		want      money.Amount
+		ok        bool
+	}{
+		// 1000 × 250 / 100
+		{100000, "ARS", "2024-01-15", "2024-03", 250000, true},
+		{-100000, "ars", "2024-01-15T10:00:00Z", "2024-03", -250000, true},
+		// 10.00 × 100 / 80 = 12.50; 0.01 × 250 / 80 rounds to 0.03
+		{1000, "ARS", "2024-02-01", "2024-01", 1250, true},
+		{1, "ARS", "2024-02-29", "2024-03", 3, true},
+		{100000, "ARS", "2024-03-31", "2024-03", 100000, true},
+		// A month without a value, on either side
+		{100000, "ARS", "2023-12-31", "2024-03", 0, false},
+		{100000, "ARS", "2024-01-15", "2024-05", 0, false},
+		{100000, "ARS", "2024-04-02", "2024-03", 0, false},
+		// Other currencies only adjust with their own series
+		{100000, "USD", "2024-01-15", "2024-03", 0, false},
+		{100000, "EUR", "2024-03-01", "2024-03", 0, false},
+		{100000, "ARS", "2024", "2024-03", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := table.Adjust(tt.amount, tt.currency, tt.date, tt.ref)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Adjust(%s %s on %s to %s) = %s, %v, want %s, %v", tt.amount, tt.currency, tt.date, tt.ref, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if !table.HasMonth("2024-03") || table.HasMonth("2024-04") {
+		t.Error("HasMonth does not match the stored months")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	got, err := readCSV(strings.NewReader("Month, Value, Currency\n2024-01, 4257.06,\n2024-02-01,4825.79, usd\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Index{
+		{Month: "2024-01", Value: 4257.06},
+		{Month: "2024-02-01", Value: 4825.79, Currency: "usd"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readCSV = %+v, want %+v", got, want)
+	}
+
+	if got, err := readCSV(strings.NewReader("")); err != nil || got != nil {
+		t.Errorf("readCSV of an empty file = %+v, %v", got, err)
+	}
+	for _, content := range []string{
+		"month\n2024-01\n",
+		"month,value\n2024-01,n/a\n",
+	} {
+		if got, err := readCSV(strings.NewReader(content)); err == nil {
+			t.Errorf("readCSV(%q) = %+v, want an error", content, got)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// YYYY-MM-DD months are cut to the month; values without a currency get
+	// the one given
+	path := write("ipc.csv", "month,value,currency\n2024-01-01,4257.06,\n2024-02,4825.79,eur\n")
+	got, err := ReadFile(path, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Index{
+		{Currency: "USD", Month: "2024-01", Value: 4257.06},
+		{Currency: "EUR", Month: "2024-02", Value: 4825.79},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadFile = %+v, want %+v", got, want)
+	}
+
+	// JSON files, with ARS as the default currency
+	path = write("ipc.json", `{"index": [{"month": "2024-03-01", "value": 5357.9}]}`)
+	got, err = ReadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Index{{Currency: DefaultCurrency, Month: "2024-03", Value: 5357.9}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadFile(json) = %+v, want %+v", got, want)
+	}
+
+	for _, content := range []string{
+		"month,value\n01/2024,100\n",
+		"month,value\n2024-13,100\n",
+		"month,value\n2024-01-15-01,100\n",
+		"month,value\n2024-01,0\n",
+		"month,value,currency\n2024-01,100,PESOS\n",
+	} {
+		if got, err := ReadFile(write("bad.csv", content), ""); err == nil {
+			t.Errorf("ReadFile(%q) = %+v, want an error", content, got)
+		}
+	}
+}
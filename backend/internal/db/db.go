@@ -2,9 +2,12 @@ package db
 
 import (
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/cpi"
+	"github.com/juank/finance-ai/backend/internal/fx"
 	"github.com/juank/finance-ai/backend/internal/models"
 )
 
@@ -13,16 +16,82 @@ type Database interface {
 	CreateUser(user models.User) error
 	GetUserByEmail(email string) (models.User, error)
 	GetTransactions(userID uuid.UUID) []models.Transaction
+	GetTransactionsBetween(userID uuid.UUID, from, to string) []models.Transaction
 	CreateUpload(upload models.Upload) error
 	GetUploads(userID uuid.UUID) []models.Upload
 	UpsertTransactions(txs []models.Transaction) error
+	UpdateTransactionCategories(userID uuid.UUID, txs []models.Transaction) error
+	GetTransaction(userID uuid.UUID, id string) (models.Transaction, error)
+	UpdateTransaction(tx models.Transaction) error
+
+	CreateImportProfile(profile models.ImportProfile) error
+	GetImportProfiles(userID uuid.UUID) []models.ImportProfile
+	GetImportProfile(userID, id uuid.UUID) (models.ImportProfile, error)
+	UpdateImportProfile(profile models.ImportProfile) error
+	DeleteImportProfile(userID, id uuid.UUID) error
+
+	GetClassificationRules(userID uuid.UUID) []models.ClassificationRule
+	CreateClassificationRule(rule models.ClassificationRule) error
+	UpdateClassificationRule(rule models.ClassificationRule) error
+	DeleteClassificationRule(userID, id uuid.UUID) error
+	ReorderClassificationRules(userID uuid.UUID, ids []uuid.UUID) error
+
+	GetMerchantAliases(userID uuid.UUID) []models.MerchantAlias
+	CreateMerchantAlias(alias models.MerchantAlias) error
+	UpdateMerchantAlias(alias models.MerchantAlias) error
+	DeleteMerchantAlias(userID, id uuid.UUID) error
+	UpdateTransactionMerchants(userID uuid.UUID, txs []models.Transaction) error
+
+	GetTransferLinks(userID uuid.UUID) []models.TransferLink
+	GetTransferLink(userID, id uuid.UUID) (models.TransferLink, error)
+	CreateTransferLink(link models.TransferLink) error
+	RejectTransferLink(userID, id uuid.UUID) error
+
+	GetTransferSettings(userID uuid.UUID) (models.TransferSettings, error)
+	SaveTransferSettings(settings models.TransferSettings) error
+
+	GetDuplicateCandidates(userID uuid.UUID) []models.DuplicateCandidate
+	GetDuplicateCandidate(userID, id uuid.UUID) (models.DuplicateCandidate, error)
+	CreateDuplicateCandidate(candidate models.DuplicateCandidate) error
+	UpdateDuplicateCandidate(candidate models.DuplicateCandidate) error
+
+	GetAccounts(userID uuid.UUID) []models.Account
+	GetAccount(userID, id uuid.UUID) (models.Account, error)
+	CreateAccount(account models.Account) error
+	UpdateAccount(account models.Account) error
+	DeleteAccount(userID, id uuid.UUID) error
+
+	GetFXRates() []fx.Rate
+	SaveFXRates(rates []fx.Rate) error
+
+	GetPriceIndex() []cpi.Index
+	SavePriceIndex(values []cpi.Index) error
 }
 
+// ErrNotFound is returned when a record does not exist or belongs to another user
+var ErrNotFound = errors.New("not found")
+
+// ErrUserExists is returned when registering an email that is already taken
+var ErrUserExists = errors.New("user already exists")
+
+// ErrConflict is returned when a record clashes with an existing one or is
+// still referenced by others
+var ErrConflict = errors.New("conflict")
+
 // Mock DB for initial development
 type MemoryDB struct {
 	users        map[string]models.User
 	transactions map[string]models.Transaction
 	uploads      []models.Upload
+	profiles     map[uuid.UUID]models.ImportProfile
+	rules        map[uuid.UUID]models.ClassificationRule
+	aliases      map[uuid.UUID]models.MerchantAlias
+	links        map[uuid.UUID]models.TransferLink
+	settings     map[uuid.UUID]models.TransferSettings
+	duplicates   map[uuid.UUID]models.DuplicateCandidate
+	accounts     map[uuid.UUID]models.Account
+	fxRates      map[string]fx.Rate
+	priceIndex   map[string]cpi.Index
 	mu           sync.RWMutex
 }
 
@@ -46,6 +115,15 @@ func GetMemoryDB() *MemoryDB {
 		users:        make(map[string]models.User),
 		transactions: make(map[string]models.Transaction),
 		uploads:      []models.Upload{},
+		profiles:     make(map[uuid.UUID]models.ImportProfile),
+		rules:        make(map[uuid.UUID]models.ClassificationRule),
+		aliases:      make(map[uuid.UUID]models.MerchantAlias),
+		links:        make(map[uuid.UUID]models.TransferLink),
+		settings:     make(map[uuid.UUID]models.TransferSettings),
+		duplicates:   make(map[uuid.UUID]models.DuplicateCandidate),
+		accounts:     make(map[uuid.UUID]models.Account),
+		fxRates:      make(map[string]fx.Rate),
+		priceIndex:   make(map[string]cpi.Index),
 	}
 }
 
@@ -53,7 +131,7 @@ func (db *MemoryDB) CreateUser(user models.User) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 	if _, exists := db.users[user.Email]; exists {
-		return errors.New("user already exists")
+		return ErrUserExists
 	}
 	db.users[user.Email] = user
 	return nil
@@ -64,7 +142,7 @@ func (db *MemoryDB) GetUserByEmail(email string) (models.User, error) {
 	defer db.mu.RUnlock()
 	user, exists := db.users[email]
 	if !exists {
-		return models.User{}, errors.New("user not found")
+		return models.User{}, ErrNotFound
 	}
 	return user, nil
 }
@@ -78,6 +156,31 @@ func (db *MemoryDB) GetTransactions(userID uuid.UUID) []models.Transaction {
 			result = append(result, tx)
 		}
 	}
+	sortTransactions(result)
+	return result
+}
+
+// sortTransactions orders txs newest first, by ID within a day, like the Postgres queries
+func sortTransactions(txs []models.Transaction) {
+	sort.Slice(txs, func(i, j int) bool {
+		if di, dj := dayOf(txs[i].Date), dayOf(txs[j].Date); di != dj {
+			return di > dj
+		}
+		return txs[i].ID < txs[j].ID
+	})
+}
+
+// GetTransactionsBetween returns the user's transactions dated from..to (YYYY-MM-DD, inclusive)
+func (db *MemoryDB) GetTransactionsBetween(userID uuid.UUID, from, to string) []models.Transaction {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var result []models.Transaction
+	for _, tx := range db.transactions {
+		if day := dayOf(tx.Date); tx.UserID == userID && day >= from && day <= to {
+			result = append(result, tx)
+		}
+	}
+	sortTransactions(result)
 	return result
 }
 
@@ -97,6 +200,9 @@ func (db *MemoryDB) GetUploads(userID uuid.UUID) []models.Upload {
 			result = append(result, u)
 		}
 	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
 	return result
 }
 
@@ -104,7 +210,516 @@ func (db *MemoryDB) UpsertTransactions(txs []models.Transaction) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 	for _, tx := range txs {
+		if stored, ok := db.transactions[tx.ID]; ok {
+			keepUserEdits(&tx, stored)
+		}
 		db.transactions[tx.ID] = tx
 	}
 	return nil
 }
+
+// keepUserEdits copies notes and user-locked fields from the stored version of a re-imported transaction
+func keepUserEdits(tx *models.Transaction, stored models.Transaction) {
+	tx.Notes = stored.Notes
+	tx.CategoryLocked = stored.CategoryLocked
+	tx.SubcategoryLocked = stored.SubcategoryLocked
+	tx.MerchantLocked = stored.MerchantLocked
+	if stored.CategoryLocked {
+		tx.Category = stored.Category
+		tx.Provenance = stored.Provenance
+		tx.NeedsReview = false
+	}
+	if stored.SubcategoryLocked {
+		tx.Subcategory = stored.Subcategory
+	}
+	if stored.MerchantLocked {
+		tx.Merchant = stored.Merchant
+	}
+	if tx.RawMerchant == nil {
+		tx.RawMerchant = stored.RawMerchant
+	}
+}
+
+// UpdateTransactionCategories stores only the category, subcategory, provenance,
+// model suggestion and review flag of txs
+func (db *MemoryDB) UpdateTransactionCategories(userID uuid.UUID, txs []models.Transaction) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, tx := range txs {
+		stored, ok := db.transactions[tx.ID]
+		if !ok || stored.UserID != userID {
+			continue
+		}
+		if !stored.CategoryLocked {
+			stored.Category = tx.Category
+			stored.Provenance = tx.Provenance
+			stored.SuggestedCategory, stored.SuggestedSubcategory = tx.SuggestedCategory, tx.SuggestedSubcategory
+			stored.CategoryConfidence = tx.CategoryConfidence
+		}
+		if !stored.SubcategoryLocked {
+			stored.Subcategory = tx.Subcategory
+		}
+		stored.NeedsReview = tx.NeedsReview && !stored.CategoryLocked
+		db.transactions[tx.ID] = stored
+	}
+	return nil
+}
+
+func (db *MemoryDB) GetTransaction(userID uuid.UUID, id string) (models.Transaction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	tx, ok := db.transactions[id]
+	if !ok || tx.UserID != userID {
+		return models.Transaction{}, ErrNotFound
+	}
+	return tx, nil
+}
+
+// UpdateTransaction stores the user-editable fields of tx, their lock flags and provenance
+func (db *MemoryDB) UpdateTransaction(tx models.Transaction) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored, ok := db.transactions[tx.ID]
+	if !ok || stored.UserID != tx.UserID {
+		return ErrNotFound
+	}
+	stored.Category, stored.Subcategory, stored.Merchant, stored.Notes = tx.Category, tx.Subcategory, tx.Merchant, tx.Notes
+	stored.CategoryLocked, stored.SubcategoryLocked, stored.MerchantLocked = tx.CategoryLocked, tx.SubcategoryLocked, tx.MerchantLocked
+	stored.NeedsReview = tx.NeedsReview
+	stored.Provenance = tx.Provenance
+	stored.DuplicateOf = tx.DuplicateOf
+	db.transactions[tx.ID] = stored
+	return nil
+}
+
+func (db *MemoryDB) CreateImportProfile(profile models.ImportProfile) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.profiles[profile.ID] = profile
+	return nil
+}
+
+func (db *MemoryDB) GetImportProfiles(userID uuid.UUID) []models.ImportProfile {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := []models.ImportProfile{}
+	for _, p := range db.profiles {
+		if p.UserID == userID {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (db *MemoryDB) GetImportProfile(userID, id uuid.UUID) (models.ImportProfile, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	p, exists := db.profiles[id]
+	if !exists || p.UserID != userID {
+		return models.ImportProfile{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (db *MemoryDB) UpdateImportProfile(profile models.ImportProfile) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	existing, exists := db.profiles[profile.ID]
+	if !exists || existing.UserID != profile.UserID {
+		return ErrNotFound
+	}
+	profile.CreatedAt = existing.CreatedAt
+	db.profiles[profile.ID] = profile
+	return nil
+}
+
+func (db *MemoryDB) DeleteImportProfile(userID, id uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	p, exists := db.profiles[id]
+	if !exists || p.UserID != userID {
+		return ErrNotFound
+	}
+	delete(db.profiles, id)
+	return nil
+}
+
+func (db *MemoryDB) GetClassificationRules(userID uuid.UUID) []models.ClassificationRule {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := []models.ClassificationRule{}
+	for _, r := range db.rules {
+		if r.UserID == userID {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority < result[j].Priority
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (db *MemoryDB) CreateClassificationRule(rule models.ClassificationRule) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rules[rule.ID] = rule
+	return nil
+}
+
+func (db *MemoryDB) UpdateClassificationRule(rule models.ClassificationRule) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	existing, exists := db.rules[rule.ID]
+	if !exists || existing.UserID != rule.UserID {
+		return ErrNotFound
+	}
+	rule.CreatedAt = existing.CreatedAt
+	db.rules[rule.ID] = rule
+	return nil
+}
+
+func (db *MemoryDB) DeleteClassificationRule(userID, id uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	r, exists := db.rules[id]
+	if !exists || r.UserID != userID {
+		return ErrNotFound
+	}
+	delete(db.rules, id)
+	return nil
+}
+
+// ReorderClassificationRules sets priorities following ids; every id must belong to the user
+func (db *MemoryDB) ReorderClassificationRules(userID uuid.UUID, ids []uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, id := range ids {
+		if r, exists := db.rules[id]; !exists || r.UserID != userID {
+			return ErrNotFound
+		}
+	}
+	for i, id := range ids {
+		r := db.rules[id]
+		r.Priority = i
+		db.rules[id] = r
+	}
+	return nil
+}
+
+func (db *MemoryDB) GetMerchantAliases(userID uuid.UUID) []models.MerchantAlias {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := []models.MerchantAlias{}
+	for _, a := range db.aliases {
+		if a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Canonical != result[j].Canonical {
+			return result[i].Canonical < result[j].Canonical
+		}
+		return result[i].Alias < result[j].Alias
+	})
+	return result
+}
+
+func (db *MemoryDB) CreateMerchantAlias(alias models.MerchantAlias) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.aliasTaken(alias) {
+		return ErrConflict
+	}
+	db.aliases[alias.ID] = alias
+	return nil
+}
+
+func (db *MemoryDB) UpdateMerchantAlias(alias models.MerchantAlias) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	existing, exists := db.aliases[alias.ID]
+	if !exists || existing.UserID != alias.UserID {
+		return ErrNotFound
+	}
+	if db.aliasTaken(alias) {
+		return ErrConflict
+	}
+	alias.CreatedAt = existing.CreatedAt
+	db.aliases[alias.ID] = alias
+	return nil
+}
+
+func (db *MemoryDB) DeleteMerchantAlias(userID, id uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	a, exists := db.aliases[id]
+	if !exists || a.UserID != userID {
+		return ErrNotFound
+	}
+	delete(db.aliases, id)
+	return nil
+}
+
+// aliasTaken reports whether another alias of the user has the same text
+func (db *MemoryDB) aliasTaken(alias models.MerchantAlias) bool {
+	for _, a := range db.aliases {
+		if a.ID != alias.ID && a.UserID == alias.UserID && a.Alias == alias.Alias {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTransactionMerchants stores only the merchant and raw merchant of txs,
+// leaving merchants locked by the user untouched
+func (db *MemoryDB) UpdateTransactionMerchants(userID uuid.UUID, txs []models.Transaction) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, tx := range txs {
+		stored, ok := db.transactions[tx.ID]
+		if !ok || stored.UserID != userID || stored.MerchantLocked {
+			continue
+		}
+		stored.Merchant, stored.RawMerchant = tx.Merchant, tx.RawMerchant
+		db.transactions[tx.ID] = stored
+	}
+	return nil
+}
+
+func (db *MemoryDB) GetTransferLinks(userID uuid.UUID) []models.TransferLink {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := []models.TransferLink{}
+	for _, l := range db.links {
+		if l.UserID == userID {
+			result = append(result, l)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (db *MemoryDB) GetTransferLink(userID, id uuid.UUID) (models.TransferLink, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	l, exists := db.links[id]
+	if !exists || l.UserID != userID {
+		return models.TransferLink{}, ErrNotFound
+	}
+	return l, nil
+}
+
+func (db *MemoryDB) CreateTransferLink(link models.TransferLink) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.links[link.ID] = link
+	return nil
+}
+
+// RejectTransferLink keeps the link with method "rejected" so it is not matched again
+func (db *MemoryDB) RejectTransferLink(userID, id uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	l, exists := db.links[id]
+	if !exists || l.UserID != userID {
+		return ErrNotFound
+	}
+	l.Method = models.LinkRejected
+	db.links[id] = l
+	return nil
+}
+
+func (db *MemoryDB) GetTransferSettings(userID uuid.UUID) (models.TransferSettings, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	s, exists := db.settings[userID]
+	if !exists {
+		return models.TransferSettings{}, ErrNotFound
+	}
+	return s, nil
+}
+
+func (db *MemoryDB) SaveTransferSettings(settings models.TransferSettings) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.settings[settings.UserID] = settings
+	return nil
+}
+
+func (db *MemoryDB) GetDuplicateCandidates(userID uuid.UUID) []models.DuplicateCandidate {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := []models.DuplicateCandidate{}
+	for _, c := range db.duplicates {
+		if c.UserID == userID {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (db *MemoryDB) GetDuplicateCandidate(userID, id uuid.UUID) (models.DuplicateCandidate, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	c, exists := db.duplicates[id]
+	if !exists || c.UserID != userID {
+		return models.DuplicateCandidate{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (db *MemoryDB) CreateDuplicateCandidate(candidate models.DuplicateCandidate) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.duplicates[candidate.ID] = candidate
+	return nil
+}
+
+// UpdateDuplicateCandidate stores the status of a reviewed candidate
+func (db *MemoryDB) UpdateDuplicateCandidate(candidate models.DuplicateCandidate) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored, exists := db.duplicates[candidate.ID]
+	if !exists || stored.UserID != candidate.UserID {
+		return ErrNotFound
+	}
+	stored.Status, stored.ResolvedAt = candidate.Status, candidate.ResolvedAt
+	db.duplicates[candidate.ID] = stored
+	return nil
+}
+
+func (db *MemoryDB) GetAccounts(userID uuid.UUID) []models.Account {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := []models.Account{}
+	for _, a := range db.accounts {
+		if a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Institution != result[j].Institution {
+			return result[i].Institution < result[j].Institution
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (db *MemoryDB) GetAccount(userID, id uuid.UUID) (models.Account, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	a, exists := db.accounts[id]
+	if !exists || a.UserID != userID {
+		return models.Account{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (db *MemoryDB) CreateAccount(account models.Account) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.accountTaken(account) {
+		return ErrConflict
+	}
+	db.accounts[account.ID] = account
+	return nil
+}
+
+func (db *MemoryDB) UpdateAccount(account models.Account) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored, exists := db.accounts[account.ID]
+	if !exists || stored.UserID != account.UserID {
+		return ErrNotFound
+	}
+	if db.accountTaken(account) {
+		return ErrConflict
+	}
+	account.CreatedAt = stored.CreatedAt
+	db.accounts[account.ID] = account
+	return nil
+}
+
+// DeleteAccount removes an account that no transaction belongs to
+func (db *MemoryDB) DeleteAccount(userID, id uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	a, exists := db.accounts[id]
+	if !exists || a.UserID != userID {
+		return ErrNotFound
+	}
+	for _, tx := range db.transactions {
+		if tx.AccountID != nil && *tx.AccountID == id {
+			return ErrConflict
+		}
+	}
+	delete(db.accounts, id)
+	return nil
+}
+
+// accountTaken reports whether another account of the user has the same
+// institution and parser account
+func (db *MemoryDB) accountTaken(account models.Account) bool {
+	for _, a := range db.accounts {
+		if a.ID != account.ID && a.UserID == account.UserID && a.Institution == account.Institution && a.ParserAccount == account.ParserAccount {
+			return true
+		}
+	}
+	return false
+}
+
+// GetFXRates returns the stored exchange rates of every type
+func (db *MemoryDB) GetFXRates() []fx.Rate {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := make([]fx.Rate, 0, len(db.fxRates))
+	for _, r := range db.fxRates {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// SaveFXRates stores rates, replacing the rate of the same type, pair and date
+func (db *MemoryDB) SaveFXRates(rates []fx.Rate) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, r := range rates {
+		db.fxRates[r.Type+":"+r.Base+"/"+r.Quote+"@"+r.Date] = r
+	}
+	return nil
+}
+
+// GetPriceIndex returns the stored monthly price index values of every currency
+func (db *MemoryDB) GetPriceIndex() []cpi.Index {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	result := make([]cpi.Index, 0, len(db.priceIndex))
+	for _, v := range db.priceIndex {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Month < result[j].Month })
+	return result
+}
+
+// SavePriceIndex stores values, replacing the value of the same currency and month
+func (db *MemoryDB) SavePriceIndex(values []cpi.Index) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, v := range values {
+		db.priceIndex[v.Currency+"@"+v.Month] = v
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Migrations are numbered pairs of files in migrations/, embedded in the binary:
+//
+//	0002_add_budgets.up.sql
+//	0002_add_budgets.down.sql
+//
+// Applied versions are recorded in schema_migrations.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationLock is the advisory lock key that serializes servers migrating the
+// same database
+const migrationLock = 72616201
+
+// Migration is one numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationState is a migration and when it was applied, nil while pending
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations in version order
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		m := migrationFileRegex.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_name.up.sql or NNNN_name.down.sql", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, m[2])
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if m[3] == "up" {
+			migration.up = string(body)
+		} else {
+			migration.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %d_%s: versions must be numbered 1, 2, 3... without gaps", m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrate applies the pending migrations in order, each in its own
+// transaction, and returns the ones applied. It fails when the database has a
+// version this binary does not know, i.e. it was migrated by a newer release.
+func (db *PostgresDB) Migrate() ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, m := range migrations {
+		done, err := db.runMigration(m, len(migrations), true)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if done {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, and
+// returns the ones reverted
+func (db *PostgresDB) MigrateDown(steps int) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var reverted []Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		done, err := db.runMigration(m, len(migrations), false)
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if done {
+			reverted = append(reverted, m)
+		}
+	}
+	return reverted, nil
+}
+
+// runMigration applies (up) or reverts m unless that was already done, holding
+// the migration lock so concurrent servers run each migration once
+func (db *PostgresDB) runMigration(m Migration, latest int, up bool) (bool, error) {
+	txn, err := db.Conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer txn.Rollback()
+
+	if err := lockMigrations(txn); err != nil {
+		return false, err
+	}
+	version, err := schemaVersion(txn)
+	if err != nil {
+		return false, err
+	}
+	if version > latest {
+		return false, fmt.Errorf("database is at version %d, newer than this binary's %d", version, latest)
+	}
+
+	var exists bool
+	if err := txn.QueryRow("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", m.Version).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists == up {
+		return false, nil
+	}
+	if up {
+		_, err = txn.Exec(m.up)
+		if err == nil {
+			_, err = txn.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+		}
+	} else {
+		_, err = txn.Exec(m.down)
+		if err == nil {
+			_, err = txn.Exec("DELETE FROM schema_migrations WHERE version = $1", m.Version)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, txn.Commit()
+}
+
+// lockMigrations takes the migration lock until txn ends and creates the
+// schema_migrations table
+func lockMigrations(txn *sql.Tx) error {
+	if _, err := txn.Exec("SELECT pg_advisory_xact_lock($1)", migrationLock); err != nil {
+		return err
+	}
+	_, err := txn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`)
+	return err
+}
+
+// schemaVersion returns the newest applied migration, 0 when none is
+func schemaVersion(txn *sql.Tx) (int, error) {
+	var version int
+	err := txn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// MigrationStatus lists the embedded migrations with when they were applied,
+// and returns the schema version of the database: the newest applied migration,
+// 0 when none is
+func (db *PostgresDB) MigrationStatus() ([]MigrationState, int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, 0, err
+	}
+	txn, err := db.Conn.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer txn.Rollback()
+	if err := lockMigrations(txn); err != nil {
+		return nil, 0, err
+	}
+
+	appliedAt := make(map[int]time.Time)
+	rows, err := txn.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, 0, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	version, err := schemaVersion(txn)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Migration: m}
+		if at, ok := appliedAt[m.Version]; ok {
+			state.AppliedAt = &at
+		}
+		states = append(states, state)
+	}
+	return states, version, txn.Commit()
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+func TestMigrationsEmbedded(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 || migrations[0].Name != "initial_schema" {
+		t.Fatalf("Migrations() = %+v, want initial_schema first", migrations)
+	}
+}
+
+// TestMigrateUpDown applies every migration, reverts them all and applies them
+// again, so down migrations stay in step with up migrations
+func TestMigrateUpDown(t *testing.T) {
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	pg := openTestSchema(t)
+	migrations, err := Migrations()
+	must(t, err)
+	latest := len(migrations)
+
+	assertVersion := func(want int) {
+		t.Helper()
+		states, version, err := pg.MigrationStatus()
+		must(t, err)
+		if version != want {
+			t.Errorf("schema version = %d, want %d", version, want)
+		}
+		for _, s := range states {
+			if applied := s.AppliedAt != nil; applied != (s.Version <= want) {
+				t.Errorf("migration %d applied = %v at version %d", s.Version, applied, want)
+			}
+		}
+	}
+
+	assertVersion(0)
+	applied, err := pg.Migrate()
+	must(t, err)
+	if len(applied) != latest {
+		t.Errorf("Migrate applied %d migrations, want %d", len(applied), latest)
+	}
+	assertVersion(latest)
+
+	// Nothing is pending the second time
+	applied, err = pg.Migrate()
+	must(t, err)
+	if len(applied) != 0 {
+		t.Errorf("second Migrate applied %+v", applied)
+	}
+
+	reverted, err := pg.MigrateDown(latest)
+	must(t, err)
+	if len(reverted) != latest {
+		t.Errorf("MigrateDown reverted %d migrations, want %d", len(reverted), latest)
+	}
+	assertVersion(0)
+
+	_, err = pg.Migrate()
+	must(t, err)
+	assertVersion(latest)
+
+	// A database migrated by a newer release is left alone
+	_, err = pg.Conn.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, 'from_the_future')", latest+1)
+	must(t, err)
+	if _, err := pg.Migrate(); err == nil {
+		t.Error("Migrate of a database newer than the binary succeeded")
+	}
+}
+
+// TestMigrateBaselineDatabase migrates databases created from the original
+// docker/db/init.sql, which is migration 1, and checks they end up with the
+// same schema as fresh ones: rows stored before keep loading and every
+// conformance scenario passes
+func TestMigrateBaselineDatabase(t *testing.T) {
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	migrations, err := Migrations()
+	must(t, err)
+
+	openBaseline := func(t *testing.T) *PostgresDB {
+		pg := openTestSchema(t)
+		_, err := pg.Conn.Exec(migrations[0].up)
+		must(t, err)
+		applied, err := pg.Migrate()
+		must(t, err)
+		if len(applied) != len(migrations) {
+			t.Fatalf("Migrate applied %d migrations, want %d", len(applied), len(migrations))
+		}
+		return pg
+	}
+
+	t.Run("ExistingRows", func(t *testing.T) {
+		pg := openTestSchema(t)
+		_, err := pg.Conn.Exec(migrations[0].up)
+		must(t, err)
+		userID := uuid.New()
+		_, err = pg.Conn.Exec("INSERT INTO users (id, email, password_hash) VALUES ($1, 'old@example.com', 'x')", userID)
+		must(t, err)
+		_, err = pg.Conn.Exec(`
+			INSERT INTO transactions (id, user_id, date, amount, source, description, currency)
+			VALUES ('tx-old', $1, '2023-05-02', -1234.50, 'galicia', 'SUPERMERCADO', 'ARS')`, userID)
+		must(t, err)
+
+		_, err = pg.Migrate()
+		must(t, err)
+		got, err := pg.GetTransaction(userID, "tx-old")
+		must(t, err)
+		if got.Amount != money.MustParse("-1234.50") || got.Description != "SUPERMERCADO" || got.CategoryLocked || got.IsCardPayment {
+			t.Errorf("migrated transaction = %+v", got)
+		}
+	})
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.name, func(t *testing.T) { s.run(t, openBaseline(t)) })
+	}
+}
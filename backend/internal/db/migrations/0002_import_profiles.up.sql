@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    separator VARCHAR(4) NOT NULL DEFAULT '',
+    header_marker VARCHAR(255) NOT NULL DEFAULT '',
+    date_column VARCHAR(255) NOT NULL,
+    date_layout VARCHAR(50) NOT NULL,
+    amount_column VARCHAR(255) NOT NULL DEFAULT '',
+    debit_column VARCHAR(255) NOT NULL DEFAULT '',
+    credit_column VARCHAR(255) NOT NULL DEFAULT '',
+    decimal_style VARCHAR(10) NOT NULL DEFAULT '',
+    description_column VARCHAR(255) NOT NULL,
+    currency_column VARCHAR(255) NOT NULL DEFAULT '',
+    currency VARCHAR(10) NOT NULL DEFAULT '',
+    source VARCHAR(50) NOT NULL,
+    account VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,13 @@
+-- Rules with a NULL user_id are global and apply to every user
+CREATE TABLE IF NOT EXISTS classification_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    conditions JSONB NOT NULL DEFAULT '{}',
+    category VARCHAR(100) NOT NULL,
+    subcategory VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_classification_rules_user ON classification_rules (user_id, priority);
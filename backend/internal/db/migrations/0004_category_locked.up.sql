@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS category_locked BOOLEAN DEFAULT FALSE;
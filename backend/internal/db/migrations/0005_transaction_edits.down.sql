@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS subcategory_locked,
+    DROP COLUMN IF EXISTS merchant_locked;
@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS notes TEXT,
+    ADD COLUMN IF NOT EXISTS subcategory_locked BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS merchant_locked BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS suggested_category,
+    DROP COLUMN IF EXISTS suggested_subcategory,
+    DROP COLUMN IF EXISTS category_confidence,
+    DROP COLUMN IF EXISTS needs_review;
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS suggested_category VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS suggested_subcategory VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS category_confidence DECIMAL(3, 2),
+    ADD COLUMN IF NOT EXISTS needs_review BOOLEAN DEFAULT FALSE;
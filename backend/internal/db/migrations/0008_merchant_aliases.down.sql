@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS merchant_aliases;
+ALTER TABLE transactions DROP COLUMN IF EXISTS raw_merchant;
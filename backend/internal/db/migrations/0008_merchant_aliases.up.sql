@@ -0,0 +1,10 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS raw_merchant VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS merchant_aliases (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL,
+    canonical VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, alias)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS transfer_links (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    debit_id VARCHAR(255) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    credit_id VARCHAR(255) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    method VARCHAR(20) NOT NULL, -- auto, manual or rejected
+    score DECIMAL(4, 2) NOT NULL DEFAULT 0,
+    fx_rate DECIMAL(18, 8),
+    fx_rate_date DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_links_user ON transfer_links (user_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS transfer_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    amount_tolerance DECIMAL(6, 4),
+    fx_spread DECIMAL(6, 4),
+    days_before INT,
+    days_after INT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
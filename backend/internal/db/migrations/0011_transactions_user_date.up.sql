@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_user_date ON transactions (user_id, date);
@@ -0,0 +1,2 @@
+ALTER TABLE transfer_links DROP COLUMN IF EXISTS kind;
+ALTER TABLE transactions DROP COLUMN IF EXISTS is_card_payment;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS is_card_payment BOOLEAN DEFAULT FALSE;
+
+ALTER TABLE transfer_links ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'transfer'; -- transfer or card_payment
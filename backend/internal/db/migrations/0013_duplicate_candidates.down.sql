@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS duplicate_candidates;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS account,
+    DROP COLUMN IF EXISTS duplicate_of;
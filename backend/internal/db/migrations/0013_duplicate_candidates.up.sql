@@ -0,0 +1,17 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS account VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS duplicate_of VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS duplicate_candidates (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id VARCHAR(255) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    duplicate_id VARCHAR(255) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    score DECIMAL(3, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL, -- pending, merged or dismissed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (transaction_id, duplicate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_duplicate_candidates_user ON duplicate_candidates (user_id, status);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+    date DATE NOT NULL,
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate_type VARCHAR(20) NOT NULL, -- official, mep, ccl or card
+    rate DECIMAL(20, 10) NOT NULL,
+    PRIMARY KEY (rate_type, base, quote, date)
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS price_index (
+    currency CHAR(3) NOT NULL, -- currency whose prices the index tracks (ARS for INDEC IPC)
+    month CHAR(7) NOT NULL, -- YYYY-MM
+    value DECIMAL(18, 6) NOT NULL,
+    PRIMARY KEY (currency, month)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS accounts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    institution VARCHAR(50) NOT NULL, -- parser source, e.g. brubank
+    parser_account VARCHAR(100) NOT NULL, -- parser account, e.g. caja_ahorro_pesos
+    type VARCHAR(20) NOT NULL, -- savings, checking, credit_card or wallet
+    currency VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    number VARCHAR(50), -- masked, e.g. ****1234
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, institution, parser_account)
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS account_id UUID REFERENCES accounts(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account ON transactions (account_id);
@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS value_date,
+    DROP COLUMN IF EXISTS direction,
+    DROP COLUMN IF EXISTS balance;
@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS value_date DATE,
+    ADD COLUMN IF NOT EXISTS direction VARCHAR(10), -- debit or credit
+    ADD COLUMN IF NOT EXISTS balance DECIMAL(15, 2);
@@ -0,0 +1,3 @@
+ALTER TABLE transfer_links
+    DROP COLUMN IF EXISTS debit_account,
+    DROP COLUMN IF EXISTS credit_account;
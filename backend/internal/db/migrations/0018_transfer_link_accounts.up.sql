@@ -0,0 +1,11 @@
+-- Account of each side, the account ID or source/account, backfilled from the
+-- linked transactions
+ALTER TABLE transfer_links
+    ADD COLUMN IF NOT EXISTS debit_account VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS credit_account VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE transfer_links l SET debit_account = COALESCE(t.account_id::text, COALESCE(t.source, '') || '/' || COALESCE(t.account, ''))
+FROM transactions t WHERE t.id = l.debit_id AND l.debit_account = '';
+
+UPDATE transfer_links l SET credit_account = COALESCE(t.account_id::text, COALESCE(t.source, '') || '/' || COALESCE(t.account, ''))
+FROM transactions t WHERE t.id = l.credit_id AND l.credit_account = '';
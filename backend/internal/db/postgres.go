@@ -2,12 +2,15 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/cpi"
+	"github.com/juank/finance-ai/backend/internal/fx"
 	"github.com/juank/finance-ai/backend/internal/models"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type PostgresDB struct {
@@ -42,25 +45,65 @@ func Connect() (Database, error) {
 }
 
 func (db *PostgresDB) CreateUser(user models.User) error {
-	_, err := db.Conn.Exec("INSERT INTO users (id, email, password_hash) VALUES ($1, $2, $3)",
-		user.ID, user.Email, user.PasswordHash)
+	_, err := db.Conn.Exec("INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4)",
+		user.ID, user.Email, user.PasswordHash, user.CreatedAt)
+	if pqErrorCode(err) == pqUniqueViolation {
+		return ErrUserExists
+	}
 	return err
 }
 
 func (db *PostgresDB) GetUserByEmail(email string) (models.User, error) {
 	var user models.User
-	err := db.Conn.QueryRow("SELECT id, email, password_hash FROM users WHERE email = $1",
-		email).Scan(&user.ID, &user.Email, &user.PasswordHash)
+	err := db.Conn.QueryRow("SELECT id, email, password_hash, created_at FROM users WHERE email = $1",
+		email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.User{}, ErrNotFound
+	}
 	if err != nil {
 		return models.User{}, err
 	}
 	return user, nil
 }
 
+const transactionColumns = `id, user_id, upload_id, date, amount, source, description, merchant, raw_merchant, category, subcategory, notes, currency,
+	is_transfer, is_fee, is_tax, neutralized, processed_at, suggested_category, suggested_subcategory, category_confidence, needs_review,
+	provenance, category_locked, subcategory_locked, merchant_locked, is_card_payment, account, duplicate_of, account_id, direction, balance, value_date`
+
+func scanTransaction(row interface{ Scan(...interface{}) error }) (models.Transaction, error) {
+	var tx models.Transaction
+	var provenance []byte
+	var account, direction, valueDate sql.NullString
+	err := row.Scan(&tx.ID, &tx.UserID, &tx.UploadID, &tx.Date, &tx.Amount, &tx.Source, &tx.Description, &tx.Merchant, &tx.RawMerchant, &tx.Category, &tx.Subcategory, &tx.Notes, &tx.Currency,
+		&tx.IsTransfer, &tx.IsFee, &tx.IsTax, &tx.Neutralized, &tx.ProcessedAt, &tx.SuggestedCategory, &tx.SuggestedSubcategory, &tx.CategoryConfidence, &tx.NeedsReview,
+		&provenance, &tx.CategoryLocked, &tx.SubcategoryLocked, &tx.MerchantLocked, &tx.IsCardPayment, &account, &tx.DuplicateOf, &tx.AccountID, &direction, &tx.Balance, &valueDate)
+	tx.Date = dayOf(tx.Date)
+	tx.Account, tx.Direction, tx.ValueDate = account.String, direction.String, dayOf(valueDate.String)
+	if err == nil && provenance != nil {
+		err = json.Unmarshal(provenance, &tx.Provenance)
+	}
+	return tx, err
+}
+
+// nullIfEmpty stores an empty string as NULL, e.g. for optional DATE columns
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// provenanceJSON encodes a classification provenance for a nullable JSONB column
+func provenanceJSON(p *models.ClassificationProvenance) interface{} {
+	if p == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(p)
+	return raw
+}
+
 func (db *PostgresDB) GetTransactions(userID uuid.UUID) []models.Transaction {
-	rows, err := db.Conn.Query(`
-		SELECT id, user_id, upload_id, date, amount, source, description, merchant, category, subcategory, currency, is_transfer, is_fee, is_tax, neutralized, processed_at 
-		FROM transactions WHERE user_id = $1 ORDER BY date DESC`, userID)
+	rows, err := db.Conn.Query("SELECT "+transactionColumns+" FROM transactions WHERE user_id = $1 ORDER BY date DESC, id", userID)
 	if err != nil {
 		return []models.Transaction{}
 	}
@@ -68,17 +111,49 @@ func (db *PostgresDB) GetTransactions(userID uuid.UUID) []models.Transaction {
 
 	var txs []models.Transaction
 	for rows.Next() {
-		var tx models.Transaction
-		var dateStr string
-		err := rows.Scan(&tx.ID, &tx.UserID, &tx.UploadID, &dateStr, &tx.Amount, &tx.Source, &tx.Description, &tx.Merchant, &tx.Category, &tx.Subcategory, &tx.Currency, &tx.IsTransfer, &tx.IsFee, &tx.IsTax, &tx.Neutralized, &tx.ProcessedAt)
-		if err == nil {
-			tx.Date = dateStr
+		if tx, err := scanTransaction(rows); err == nil {
 			txs = append(txs, tx)
 		}
 	}
 	return txs
 }
 
+// GetTransactionsBetween returns the user's transactions dated from..to (YYYY-MM-DD, inclusive)
+func (db *PostgresDB) GetTransactionsBetween(userID uuid.UUID, from, to string) []models.Transaction {
+	rows, err := db.Conn.Query("SELECT "+transactionColumns+" FROM transactions WHERE user_id = $1 AND date BETWEEN $2 AND $3 ORDER BY date DESC, id", userID, from, to)
+	if err != nil {
+		return []models.Transaction{}
+	}
+	defer rows.Close()
+
+	var txs []models.Transaction
+	for rows.Next() {
+		if tx, err := scanTransaction(rows); err == nil {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+func (db *PostgresDB) GetTransaction(userID uuid.UUID, id string) (models.Transaction, error) {
+	tx, err := scanTransaction(db.Conn.QueryRow("SELECT "+transactionColumns+" FROM transactions WHERE id = $1 AND user_id = $2", id, userID))
+	if err == sql.ErrNoRows {
+		return tx, ErrNotFound
+	}
+	return tx, err
+}
+
+// UpdateTransaction stores the user-editable fields of tx and their lock flags
+func (db *PostgresDB) UpdateTransaction(tx models.Transaction) error {
+	res, err := db.Conn.Exec(`
+		UPDATE transactions SET category = $3, subcategory = $4, merchant = $5, notes = $6,
+			category_locked = $7, subcategory_locked = $8, merchant_locked = $9, needs_review = $10, provenance = $11, duplicate_of = $12
+		WHERE id = $1 AND user_id = $2`,
+		tx.ID, tx.UserID, tx.Category, tx.Subcategory, tx.Merchant, tx.Notes, tx.CategoryLocked, tx.SubcategoryLocked, tx.MerchantLocked, tx.NeedsReview,
+		provenanceJSON(tx.Provenance), tx.DuplicateOf)
+	return checkAffected(res, err)
+}
+
 func (db *PostgresDB) CreateUpload(upload models.Upload) error {
 	_, err := db.Conn.Exec("INSERT INTO uploads (id, user_id, filename, status, created_at) VALUES ($1, $2, $3, $4, $5)",
 		upload.ID, upload.UserID, upload.Filename, upload.Status, upload.CreatedAt)
@@ -105,21 +180,564 @@ func (db *PostgresDB) GetUploads(userID uuid.UUID) []models.Upload {
 func (db *PostgresDB) UpsertTransactions(txs []models.Transaction) error {
 	for _, tx := range txs {
 		_, err := db.Conn.Exec(`
-			INSERT INTO transactions (id, user_id, upload_id, date, amount, source, description, merchant, category, subcategory, currency, is_transfer, is_fee, is_tax, neutralized, processed_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			INSERT INTO transactions (id, user_id, upload_id, date, amount, source, description, merchant, category, subcategory, currency, is_transfer, is_fee, is_tax, neutralized, processed_at,
+				suggested_category, suggested_subcategory, category_confidence, needs_review, provenance, raw_merchant, is_card_payment,
+				account, duplicate_of, account_id, direction, balance, value_date, notes, category_locked, subcategory_locked, merchant_locked)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26,
+				$27, $28, $29, $30, $31, $32, $33)
 			ON CONFLICT (id) DO UPDATE SET
 				upload_id = EXCLUDED.upload_id,
-				category = EXCLUDED.category,
-				subcategory = EXCLUDED.subcategory,
-				merchant = EXCLUDED.merchant,
+				date = EXCLUDED.date,
+				value_date = EXCLUDED.value_date,
+				amount = EXCLUDED.amount,
+				source = EXCLUDED.source,
+				description = EXCLUDED.description,
+				direction = EXCLUDED.direction,
+				currency = EXCLUDED.currency,
+				balance = EXCLUDED.balance,
+				processed_at = EXCLUDED.processed_at,
+				category = CASE WHEN transactions.category_locked THEN transactions.category ELSE EXCLUDED.category END,
+				subcategory = CASE WHEN transactions.subcategory_locked THEN transactions.subcategory ELSE EXCLUDED.subcategory END,
+				merchant = CASE WHEN transactions.merchant_locked THEN transactions.merchant ELSE EXCLUDED.merchant END,
+				raw_merchant = COALESCE(EXCLUDED.raw_merchant, transactions.raw_merchant),
 				is_transfer = EXCLUDED.is_transfer,
 				is_fee = EXCLUDED.is_fee,
 				is_tax = EXCLUDED.is_tax,
-				neutralized = EXCLUDED.neutralized
-		`, tx.ID, tx.UserID, tx.UploadID, tx.Date, tx.Amount, tx.Source, tx.Description, tx.Merchant, tx.Category, tx.Subcategory, tx.Currency, tx.IsTransfer, tx.IsFee, tx.IsTax, tx.Neutralized, tx.ProcessedAt)
+				is_card_payment = EXCLUDED.is_card_payment,
+				account = EXCLUDED.account,
+				account_id = EXCLUDED.account_id,
+				duplicate_of = EXCLUDED.duplicate_of,
+				neutralized = EXCLUDED.neutralized,
+				suggested_category = EXCLUDED.suggested_category,
+				suggested_subcategory = EXCLUDED.suggested_subcategory,
+				category_confidence = EXCLUDED.category_confidence,
+				needs_review = EXCLUDED.needs_review AND NOT transactions.category_locked,
+				provenance = CASE WHEN transactions.category_locked THEN transactions.provenance ELSE EXCLUDED.provenance END
+		`, tx.ID, tx.UserID, tx.UploadID, tx.Date, tx.Amount, tx.Source, tx.Description, tx.Merchant, tx.Category, tx.Subcategory, tx.Currency, tx.IsTransfer, tx.IsFee, tx.IsTax, tx.Neutralized, tx.ProcessedAt,
+			tx.SuggestedCategory, tx.SuggestedSubcategory, tx.CategoryConfidence, tx.NeedsReview, provenanceJSON(tx.Provenance), tx.RawMerchant, tx.IsCardPayment,
+			tx.Account, tx.DuplicateOf, tx.AccountID, tx.Direction, tx.Balance, nullIfEmpty(tx.ValueDate), tx.Notes,
+			tx.CategoryLocked, tx.SubcategoryLocked, tx.MerchantLocked)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// UpdateTransactionCategories stores only the category, subcategory, provenance,
+// model suggestion and review flag of txs
+func (db *PostgresDB) UpdateTransactionCategories(userID uuid.UUID, txs []models.Transaction) error {
+	dbTx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	for _, tx := range txs {
+		if _, err := dbTx.Exec(`
+			UPDATE transactions SET
+				category = CASE WHEN category_locked THEN category ELSE $1 END,
+				subcategory = CASE WHEN subcategory_locked THEN subcategory ELSE $2 END,
+				needs_review = $5 AND NOT category_locked,
+				provenance = CASE WHEN category_locked THEN provenance ELSE $6 END,
+				suggested_category = CASE WHEN category_locked THEN suggested_category ELSE $7 END,
+				suggested_subcategory = CASE WHEN category_locked THEN suggested_subcategory ELSE $8 END,
+				category_confidence = CASE WHEN category_locked THEN category_confidence ELSE $9 END
+			WHERE id = $3 AND user_id = $4`,
+			tx.Category, tx.Subcategory, tx.ID, userID, tx.NeedsReview, provenanceJSON(tx.Provenance),
+			tx.SuggestedCategory, tx.SuggestedSubcategory, tx.CategoryConfidence); err != nil {
+			return err
+		}
+	}
+	return dbTx.Commit()
+}
+
+const importProfileColumns = `id, user_id, name, separator, header_marker, date_column, date_layout, amount_column, debit_column, credit_column,
+	decimal_style, description_column, currency_column, currency, source, account, created_at`
+
+func scanImportProfile(row interface{ Scan(...interface{}) error }) (models.ImportProfile, error) {
+	var p models.ImportProfile
+	err := row.Scan(&p.ID, &p.UserID, &p.Name, &p.Separator, &p.HeaderMarker, &p.DateColumn, &p.DateLayout, &p.AmountColumn, &p.DebitColumn, &p.CreditColumn,
+		&p.DecimalStyle, &p.DescriptionColumn, &p.CurrencyColumn, &p.Currency, &p.Source, &p.Account, &p.CreatedAt)
+	return p, err
+}
+
+func (db *PostgresDB) CreateImportProfile(p models.ImportProfile) error {
+	_, err := db.Conn.Exec(`INSERT INTO import_profiles (`+importProfileColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		p.ID, p.UserID, p.Name, p.Separator, p.HeaderMarker, p.DateColumn, p.DateLayout, p.AmountColumn, p.DebitColumn, p.CreditColumn,
+		p.DecimalStyle, p.DescriptionColumn, p.CurrencyColumn, p.Currency, p.Source, p.Account, p.CreatedAt)
+	return err
+}
+
+func (db *PostgresDB) GetImportProfiles(userID uuid.UUID) []models.ImportProfile {
+	profiles := []models.ImportProfile{}
+	rows, err := db.Conn.Query("SELECT "+importProfileColumns+" FROM import_profiles WHERE user_id = $1 ORDER BY name", userID)
+	if err != nil {
+		return profiles
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if p, err := scanImportProfile(rows); err == nil {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
+
+func (db *PostgresDB) GetImportProfile(userID, id uuid.UUID) (models.ImportProfile, error) {
+	p, err := scanImportProfile(db.Conn.QueryRow("SELECT "+importProfileColumns+" FROM import_profiles WHERE id = $1 AND user_id = $2", id, userID))
+	if err == sql.ErrNoRows {
+		return models.ImportProfile{}, ErrNotFound
+	}
+	return p, err
+}
+
+func (db *PostgresDB) UpdateImportProfile(p models.ImportProfile) error {
+	res, err := db.Conn.Exec(`
+		UPDATE import_profiles SET name = $3, separator = $4, header_marker = $5, date_column = $6, date_layout = $7, amount_column = $8,
+			debit_column = $9, credit_column = $10, decimal_style = $11, description_column = $12, currency_column = $13, currency = $14,
+			source = $15, account = $16
+		WHERE id = $1 AND user_id = $2`,
+		p.ID, p.UserID, p.Name, p.Separator, p.HeaderMarker, p.DateColumn, p.DateLayout, p.AmountColumn,
+		p.DebitColumn, p.CreditColumn, p.DecimalStyle, p.DescriptionColumn, p.CurrencyColumn, p.Currency, p.Source, p.Account)
+	return checkAffected(res, err)
+}
+
+func (db *PostgresDB) DeleteImportProfile(userID, id uuid.UUID) error {
+	res, err := db.Conn.Exec("DELETE FROM import_profiles WHERE id = $1 AND user_id = $2", id, userID)
+	return checkAffected(res, err)
+}
+
+// checkAffected turns an UPDATE/DELETE that matched no rows into ErrNotFound
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetClassificationRules returns the rules of a user, or the global rules for uuid.Nil
+func (db *PostgresDB) GetClassificationRules(userID uuid.UUID) []models.ClassificationRule {
+	rules := []models.ClassificationRule{}
+	rows, err := db.Conn.Query(`
+		SELECT id, user_id, name, priority, conditions, category, subcategory, created_at
+		FROM classification_rules WHERE user_id IS NOT DISTINCT FROM $1 ORDER BY priority, created_at`, nullableUser(userID))
+	if err != nil {
+		return rules
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.ClassificationRule
+		var owner uuid.NullUUID
+		var conditions []byte
+		if err := rows.Scan(&r.ID, &owner, &r.Name, &r.Priority, &conditions, &r.Category, &r.Subcategory, &r.CreatedAt); err != nil {
+			continue
+		}
+		r.UserID = owner.UUID
+		if json.Unmarshal(conditions, &r.Conditions) == nil {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+func (db *PostgresDB) CreateClassificationRule(r models.ClassificationRule) error {
+	conditions, err := json.Marshal(r.Conditions)
+	if err != nil {
+		return err
+	}
+	_, err = db.Conn.Exec(`
+		INSERT INTO classification_rules (id, user_id, name, priority, conditions, category, subcategory, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		r.ID, nullableUser(r.UserID), r.Name, r.Priority, conditions, r.Category, r.Subcategory, r.CreatedAt)
+	return err
+}
+
+func (db *PostgresDB) UpdateClassificationRule(r models.ClassificationRule) error {
+	conditions, err := json.Marshal(r.Conditions)
+	if err != nil {
+		return err
+	}
+	res, err := db.Conn.Exec(`
+		UPDATE classification_rules SET name = $3, priority = $4, conditions = $5, category = $6, subcategory = $7
+		WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2`,
+		r.ID, nullableUser(r.UserID), r.Name, r.Priority, conditions, r.Category, r.Subcategory)
+	return checkAffected(res, err)
+}
+
+func (db *PostgresDB) DeleteClassificationRule(userID, id uuid.UUID) error {
+	res, err := db.Conn.Exec("DELETE FROM classification_rules WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2", id, nullableUser(userID))
+	return checkAffected(res, err)
+}
+
+// ReorderClassificationRules sets priorities following ids in a single transaction
+func (db *PostgresDB) ReorderClassificationRules(userID uuid.UUID, ids []uuid.UUID) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		res, err := tx.Exec("UPDATE classification_rules SET priority = $3 WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2", id, nullableUser(userID), i)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// nullableUser stores global records (uuid.Nil) with a NULL user_id
+func nullableUser(userID uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil}
+}
+
+func (db *PostgresDB) GetMerchantAliases(userID uuid.UUID) []models.MerchantAlias {
+	aliases := []models.MerchantAlias{}
+	rows, err := db.Conn.Query("SELECT id, user_id, alias, canonical, created_at FROM merchant_aliases WHERE user_id = $1 ORDER BY canonical, alias", userID)
+	if err != nil {
+		return aliases
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.MerchantAlias
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Alias, &a.Canonical, &a.CreatedAt); err == nil {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases
+}
+
+func (db *PostgresDB) CreateMerchantAlias(a models.MerchantAlias) error {
+	_, err := db.Conn.Exec("INSERT INTO merchant_aliases (id, user_id, alias, canonical, created_at) VALUES ($1, $2, $3, $4, $5)",
+		a.ID, a.UserID, a.Alias, a.Canonical, a.CreatedAt)
+	if pqErrorCode(err) == pqUniqueViolation {
+		return ErrConflict
+	}
+	return err
+}
+
+func (db *PostgresDB) UpdateMerchantAlias(a models.MerchantAlias) error {
+	res, err := db.Conn.Exec("UPDATE merchant_aliases SET alias = $3, canonical = $4 WHERE id = $1 AND user_id = $2",
+		a.ID, a.UserID, a.Alias, a.Canonical)
+	if pqErrorCode(err) == pqUniqueViolation {
+		return ErrConflict
+	}
+	return checkAffected(res, err)
+}
+
+func (db *PostgresDB) DeleteMerchantAlias(userID, id uuid.UUID) error {
+	res, err := db.Conn.Exec("DELETE FROM merchant_aliases WHERE id = $1 AND user_id = $2", id, userID)
+	return checkAffected(res, err)
+}
+
+// UpdateTransactionMerchants stores only the merchant and raw merchant of txs,
+// leaving merchants locked by the user untouched
+func (db *PostgresDB) UpdateTransactionMerchants(userID uuid.UUID, txs []models.Transaction) error {
+	dbTx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	for _, tx := range txs {
+		if _, err := dbTx.Exec("UPDATE transactions SET merchant = $1, raw_merchant = $2 WHERE id = $3 AND user_id = $4 AND NOT merchant_locked",
+			tx.Merchant, tx.RawMerchant, tx.ID, userID); err != nil {
+			return err
+		}
+	}
+	return dbTx.Commit()
+}
+
+const transferLinkColumns = "id, user_id, debit_id, credit_id, debit_account, credit_account, kind, method, score, fx_rate, fx_rate_date, created_at"
+
+func scanTransferLink(row interface{ Scan(...interface{}) error }) (models.TransferLink, error) {
+	var l models.TransferLink
+	var rateDate sql.NullString
+	err := row.Scan(&l.ID, &l.UserID, &l.DebitID, &l.CreditID, &l.DebitAccount, &l.CreditAccount, &l.Kind, &l.Method, &l.Score, &l.FXRate, &rateDate, &l.CreatedAt)
+	l.FXRateDate = dayOf(rateDate.String)
+	return l, err
+}
+
+func (db *PostgresDB) GetTransferLinks(userID uuid.UUID) []models.TransferLink {
+	links := []models.TransferLink{}
+	rows, err := db.Conn.Query("SELECT "+transferLinkColumns+" FROM transfer_links WHERE user_id = $1 ORDER BY created_at DESC", userID)
+	if err != nil {
+		return links
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if l, err := scanTransferLink(rows); err == nil {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+func (db *PostgresDB) GetTransferLink(userID, id uuid.UUID) (models.TransferLink, error) {
+	l, err := scanTransferLink(db.Conn.QueryRow("SELECT "+transferLinkColumns+" FROM transfer_links WHERE id = $1 AND user_id = $2", id, userID))
+	if err == sql.ErrNoRows {
+		return models.TransferLink{}, ErrNotFound
+	}
+	return l, err
+}
+
+func (db *PostgresDB) CreateTransferLink(l models.TransferLink) error {
+	var rateDate interface{}
+	if l.FXRateDate != "" {
+		rateDate = l.FXRateDate
+	}
+	_, err := db.Conn.Exec("INSERT INTO transfer_links ("+transferLinkColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+		l.ID, l.UserID, l.DebitID, l.CreditID, l.DebitAccount, l.CreditAccount, l.Kind, l.Method, l.Score, l.FXRate, rateDate, l.CreatedAt)
+	return err
+}
+
+// RejectTransferLink keeps the link with method "rejected" so it is not matched again
+func (db *PostgresDB) RejectTransferLink(userID, id uuid.UUID) error {
+	res, err := db.Conn.Exec("UPDATE transfer_links SET method = $3 WHERE id = $1 AND user_id = $2", id, userID, models.LinkRejected)
+	return checkAffected(res, err)
+}
+
+func (db *PostgresDB) GetTransferSettings(userID uuid.UUID) (models.TransferSettings, error) {
+	s := models.TransferSettings{UserID: userID}
+	err := db.Conn.QueryRow("SELECT amount_tolerance, fx_spread, days_before, days_after, updated_at FROM transfer_settings WHERE user_id = $1", userID).
+		Scan(&s.AmountTolerance, &s.FXSpread, &s.DaysBefore, &s.DaysAfter, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return models.TransferSettings{}, ErrNotFound
+	}
+	return s, err
+}
+
+func (db *PostgresDB) SaveTransferSettings(s models.TransferSettings) error {
+	_, err := db.Conn.Exec(`
+		INSERT INTO transfer_settings (user_id, amount_tolerance, fx_spread, days_before, days_after, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET amount_tolerance = EXCLUDED.amount_tolerance, fx_spread = EXCLUDED.fx_spread,
+			days_before = EXCLUDED.days_before, days_after = EXCLUDED.days_after, updated_at = EXCLUDED.updated_at`,
+		s.UserID, s.AmountTolerance, s.FXSpread, s.DaysBefore, s.DaysAfter, s.UpdatedAt)
+	return err
+}
+
+const duplicateColumns = "id, user_id, transaction_id, duplicate_id, score, status, created_at, resolved_at"
+
+func scanDuplicateCandidate(row interface{ Scan(...interface{}) error }) (models.DuplicateCandidate, error) {
+	var c models.DuplicateCandidate
+	err := row.Scan(&c.ID, &c.UserID, &c.TransactionID, &c.DuplicateID, &c.Score, &c.Status, &c.CreatedAt, &c.ResolvedAt)
+	return c, err
+}
+
+func (db *PostgresDB) GetDuplicateCandidates(userID uuid.UUID) []models.DuplicateCandidate {
+	candidates := []models.DuplicateCandidate{}
+	rows, err := db.Conn.Query("SELECT "+duplicateColumns+" FROM duplicate_candidates WHERE user_id = $1 ORDER BY created_at DESC", userID)
+	if err != nil {
+		return candidates
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if c, err := scanDuplicateCandidate(rows); err == nil {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+func (db *PostgresDB) GetDuplicateCandidate(userID, id uuid.UUID) (models.DuplicateCandidate, error) {
+	c, err := scanDuplicateCandidate(db.Conn.QueryRow("SELECT "+duplicateColumns+" FROM duplicate_candidates WHERE id = $1 AND user_id = $2", id, userID))
+	if err == sql.ErrNoRows {
+		return models.DuplicateCandidate{}, ErrNotFound
+	}
+	return c, err
+}
+
+func (db *PostgresDB) CreateDuplicateCandidate(c models.DuplicateCandidate) error {
+	_, err := db.Conn.Exec("INSERT INTO duplicate_candidates ("+duplicateColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		c.ID, c.UserID, c.TransactionID, c.DuplicateID, c.Score, c.Status, c.CreatedAt, c.ResolvedAt)
+	return err
+}
+
+// UpdateDuplicateCandidate stores the status of a reviewed candidate
+func (db *PostgresDB) UpdateDuplicateCandidate(c models.DuplicateCandidate) error {
+	res, err := db.Conn.Exec("UPDATE duplicate_candidates SET status = $3, resolved_at = $4 WHERE id = $1 AND user_id = $2",
+		c.ID, c.UserID, c.Status, c.ResolvedAt)
+	return checkAffected(res, err)
+}
+
+const accountColumns = "id, user_id, institution, parser_account, type, currency, name, number, created_at"
+
+func scanAccount(row interface{ Scan(...interface{}) error }) (models.Account, error) {
+	var a models.Account
+	err := row.Scan(&a.ID, &a.UserID, &a.Institution, &a.ParserAccount, &a.Type, &a.Currency, &a.Name, &a.Number, &a.CreatedAt)
+	return a, err
+}
+
+// pqErrorCode returns the SQLSTATE of a Postgres error, or "" for other errors
+func pqErrorCode(err error) string {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+)
+
+func (db *PostgresDB) GetAccounts(userID uuid.UUID) []models.Account {
+	accounts := []models.Account{}
+	rows, err := db.Conn.Query("SELECT "+accountColumns+" FROM accounts WHERE user_id = $1 ORDER BY institution, name", userID)
+	if err != nil {
+		return accounts
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if a, err := scanAccount(rows); err == nil {
+			accounts = append(accounts, a)
+		}
+	}
+	return accounts
+}
+
+func (db *PostgresDB) GetAccount(userID, id uuid.UUID) (models.Account, error) {
+	a, err := scanAccount(db.Conn.QueryRow("SELECT "+accountColumns+" FROM accounts WHERE id = $1 AND user_id = $2", id, userID))
+	if err == sql.ErrNoRows {
+		return models.Account{}, ErrNotFound
+	}
+	return a, err
+}
+
+func (db *PostgresDB) CreateAccount(a models.Account) error {
+	_, err := db.Conn.Exec("INSERT INTO accounts ("+accountColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		a.ID, a.UserID, a.Institution, a.ParserAccount, a.Type, a.Currency, a.Name, a.Number, a.CreatedAt)
+	if pqErrorCode(err) == pqUniqueViolation {
+		return ErrConflict
+	}
+	return err
+}
+
+func (db *PostgresDB) UpdateAccount(a models.Account) error {
+	res, err := db.Conn.Exec(`
+		UPDATE accounts SET institution = $3, parser_account = $4, type = $5, currency = $6, name = $7, number = $8
+		WHERE id = $1 AND user_id = $2`,
+		a.ID, a.UserID, a.Institution, a.ParserAccount, a.Type, a.Currency, a.Name, a.Number)
+	if pqErrorCode(err) == pqUniqueViolation {
+		return ErrConflict
+	}
+	return checkAffected(res, err)
+}
+
+// DeleteAccount removes an account that no transaction belongs to
+func (db *PostgresDB) DeleteAccount(userID, id uuid.UUID) error {
+	res, err := db.Conn.Exec("DELETE FROM accounts WHERE id = $1 AND user_id = $2", id, userID)
+	if pqErrorCode(err) == pqForeignKeyViolation {
+		return ErrConflict
+	}
+	return checkAffected(res, err)
+}
+
+// GetFXRates returns the stored exchange rates of every type
+func (db *PostgresDB) GetFXRates() []fx.Rate {
+	rates := []fx.Rate{}
+	rows, err := db.Conn.Query("SELECT date, base, quote, rate_type, rate FROM fx_rates ORDER BY date")
+	if err != nil {
+		return rates
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r fx.Rate
+		if err := rows.Scan(&r.Date, &r.Base, &r.Quote, &r.Type, &r.Rate); err == nil {
+			r.Date = dayOf(r.Date)
+			rates = append(rates, r)
+		}
+	}
+	return rates
+}
+
+// SaveFXRates stores rates in one transaction, replacing the rate of the same
+// type, pair and date
+func (db *PostgresDB) SaveFXRates(rates []fx.Rate) error {
+	txn, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	stmt, err := txn.Prepare(`
+		INSERT INTO fx_rates (date, base, quote, rate_type, rate) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rate_type, base, quote, date) DO UPDATE SET rate = EXCLUDED.rate`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range rates {
+		if _, err := stmt.Exec(r.Date, r.Base, r.Quote, r.Type, r.Rate); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
+
+// GetPriceIndex returns the stored monthly price index values of every currency
+func (db *PostgresDB) GetPriceIndex() []cpi.Index {
+	values := []cpi.Index{}
+	rows, err := db.Conn.Query("SELECT currency, month, value FROM price_index ORDER BY month")
+	if err != nil {
+		return values
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v cpi.Index
+		if err := rows.Scan(&v.Currency, &v.Month, &v.Value); err == nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// SavePriceIndex stores values in one transaction, replacing the value of the
+// same currency and month
+func (db *PostgresDB) SavePriceIndex(values []cpi.Index) error {
+	txn, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	stmt, err := txn.Prepare(`
+		INSERT INTO price_index (currency, month, value) VALUES ($1, $2, $3)
+		ON CONFLICT (currency, month) DO UPDATE SET value = EXCLUDED.value`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, v := range values {
+		if _, err := stmt.Exec(v.Currency, v.Month, v.Value); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
+
+// dayOf trims a DATE column scanned as text (2024-01-02T00:00:00Z) to YYYY-MM-DD
+func dayOf(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
+}
@@ -0,0 +1,330 @@
+package fx
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// DefaultRatesPath is used when FX_RATES is not set
+const DefaultRatesPath = "fx_rates.json"
+
+// RefreshInterval is how long Default keeps its table before re-reading the
+// file and the store, so rates imported by another process show up
+const RefreshInterval = 10 * time.Minute
+
+// MaxStaleDays is how far back Lookup searches when a date has no rate (weekends, holidays)
+const MaxStaleDays = 7
+
+// Rate types. Argentine pesos trade at several rates on the same day.
+const (
+	RateOfficial = "official"
+	RateMEP      = "mep"
+	RateCCL      = "ccl"
+	RateCard     = "card"
+)
+
+// RateTypes lists the supported rate types
+var RateTypes = []string{RateOfficial, RateMEP, RateCCL, RateCard}
+
+// ValidRateType reports whether t is one of RateTypes
+func ValidRateType(t string) bool {
+	for _, known := range RateTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Rate is the price of one unit of Base in Quote on Date
+type Rate struct {
+	Date  string  `json:"date"` // YYYY-MM-DD
+	Base  string  `json:"base"`
+	Quote string  `json:"quote"`
+	Type  string  `json:"rate_type"` // official when empty
+	Rate  float64 `json:"rate"`
+}
+
+// Table holds daily rates indexed by rate type and currency pair
+type Table struct {
+	byPair map[string][]Rate // type:BASE/QUOTE -> rates sorted by date
+}
+
+// Store provides the rates imported into the database
+type Store interface {
+	GetFXRates() []Rate
+}
+
+type ratesFile struct {
+	Rates []Rate `json:"rates"`
+}
+
+// NewTable indexes rates; non-positive rates are ignored. When a pair has more
+// than one rate of the same type on a date, the last one wins.
+func NewTable(rates []Rate) *Table {
+	t := &Table{byPair: make(map[string][]Rate)}
+	for _, r := range rates {
+		if r.Rate <= 0 {
+			continue
+		}
+		r = normalize(r)
+		key := pairKey(r.Type, r.Base, r.Quote)
+		t.byPair[key] = append(t.byPair[key], r)
+	}
+	for key, rs := range t.byPair {
+		sort.SliceStable(rs, func(i, j int) bool { return rs[i].Date < rs[j].Date })
+		unique := rs[:0]
+		for _, r := range rs {
+			if n := len(unique); n > 0 && unique[n-1].Date == r.Date {
+				unique[n-1] = r
+				continue
+			}
+			unique = append(unique, r)
+		}
+		t.byPair[key] = unique
+	}
+	return t
+}
+
+func normalize(r Rate) Rate {
+	r.Base, r.Quote = strings.ToUpper(r.Base), strings.ToUpper(r.Quote)
+	r.Type = strings.ToLower(r.Type)
+	if r.Type == "" {
+		r.Type = RateOfficial
+	}
+	return r
+}
+
+func pairKey(rateType, base, quote string) string {
+	return rateType + ":" + base + "/" + quote
+}
+
+// LoadFile reads a {"rates": [...]} file
+func LoadFile(path string) (*Table, error) {
+	rates, err := ReadFile(path, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewTable(rates), nil
+}
+
+// ReadFile reads rates from a JSON file ({"rates": [...]}) or, for .csv files,
+// a CSV with date, base, quote and rate columns and an optional rate_type
+// column. Rates without a type get rateType, or official when it is empty.
+func ReadFile(path, rateType string) ([]Rate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rates []Rate
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		rates, err = readCSV(f)
+	} else {
+		var file ratesFile
+		err = json.NewDecoder(f).Decode(&file)
+		rates = file.Rates
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i := range rates {
+		if rates[i].Type == "" {
+			rates[i].Type = rateType
+		}
+		rates[i] = normalize(rates[i])
+		if err := validate(rates[i]); err != nil {
+			return nil, fmt.Errorf("%s: rate %d: %w", path, i+1, err)
+		}
+	}
+	return rates, nil
+}
+
+func readCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := make(map[string]int)
+	for i, h := range records[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var rates []Rate
+	for n, row := range records[1:] {
+		value, err := strconv.ParseFloat(cell(row, "rate"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", n+2, cell(row, "rate"))
+		}
+		rates = append(rates, Rate{
+			Date:  cell(row, "date"),
+			Base:  cell(row, "base"),
+			Quote: cell(row, "quote"),
+			Type:  cell(row, "rate_type"),
+			Rate:  value,
+		})
+	}
+	return rates, nil
+}
+
+func validate(r Rate) error {
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		return fmt.Errorf("invalid date %q", r.Date)
+	}
+	if len(r.Base) != 3 || len(r.Quote) != 3 || r.Base == r.Quote {
+		return fmt.Errorf("invalid currency pair %s/%s", r.Base, r.Quote)
+	}
+	if !ValidRateType(r.Type) {
+		return fmt.Errorf("unknown rate type %q", r.Type)
+	}
+	if r.Rate <= 0 {
+		return fmt.Errorf("rate must be positive")
+	}
+	return nil
+}
+
+// Lookup returns how many units of quote one unit of base was worth on date at
+// the official rate. See LookupType.
+func (t *Table) Lookup(date, base, quote string) (Rate, bool) {
+	return t.LookupType(date, RateOfficial, base, quote)
+}
+
+// LookupType returns how many units of quote one unit of base was worth on date
+// at the given rate type. It uses the latest rate on or before date, up to
+// MaxStaleDays old, and inverts the opposite pair when only that one is known.
+// The returned Rate records the rate and date actually applied.
+func (t *Table) LookupType(date, rateType, base, quote string) (Rate, bool) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if len(date) > 10 {
+		date = date[:10]
+	}
+	if base == quote {
+		return Rate{Date: date, Base: base, Quote: quote, Type: rateType, Rate: 1}, true
+	}
+	if r, ok := t.latest(pairKey(rateType, base, quote), date); ok {
+		return r, true
+	}
+	if r, ok := t.latest(pairKey(rateType, quote, base), date); ok {
+		return Rate{Date: r.Date, Base: base, Quote: quote, Type: rateType, Rate: 1 / r.Rate}, true
+	}
+	return Rate{}, false
+}
+
+// Conversion is an amount converted into another currency, with the rate used
+type Conversion struct {
+	money.Money
+	Rate     float64 `json:"rate"`
+	RateDate string  `json:"rate_date"`
+	RateType string  `json:"rate_type"`
+}
+
+// Convert converts m, dated date, into currency to
+func (t *Table) Convert(m money.Money, date, to, rateType string) (Conversion, bool) {
+	r, ok := t.LookupType(date, rateType, m.Currency, to)
+	if !ok {
+		return Conversion{}, false
+	}
+	return Conversion{
+		Money:    money.New(m.Amount.Mul(r.Rate), r.Quote),
+		Rate:     r.Rate,
+		RateDate: r.Date,
+		RateType: rateType,
+	}, true
+}
+
+func (t *Table) latest(pair, date string) (Rate, bool) {
+	if t == nil {
+		return Rate{}, false
+	}
+	rs := t.byPair[pair]
+	// First rate after date; the one before it is the latest on or before date
+	i := sort.Search(len(rs), func(i int) bool { return rs[i].Date > date })
+	if i == 0 {
+		return Rate{}, false
+	}
+	r := rs[i-1]
+
+	day, err1 := time.Parse("2006-01-02", date)
+	rateDay, err2 := time.Parse("2006-01-02", r.Date)
+	if err1 != nil || err2 != nil || day.Sub(rateDay).Hours()/24 > MaxStaleDays {
+		return Rate{}, false
+	}
+	return r, true
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultTable *Table
+	defaultStore Store
+	loadedAt     time.Time
+)
+
+// SetStore makes Default include the rates stored in s
+func SetStore(s Store) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultStore, defaultTable = s, nil
+}
+
+// Default returns the rates table loaded from FX_RATES or ./fx_rates.json,
+// together with the rates in the store set with SetStore; stored rates win
+// over the file. Without either, only same-currency amounts compare.
+func Default() *Table {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultTable == nil || time.Since(loadedAt) > RefreshInterval {
+		path := os.Getenv("FX_RATES")
+		if path == "" {
+			path = DefaultRatesPath
+		}
+		rates, err := ReadFile(path, "")
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("fx: ignoring %s: %v", path, err)
+		}
+		if defaultStore != nil {
+			rates = append(rates, defaultStore.GetFXRates()...)
+		}
+		defaultTable, loadedAt = NewTable(rates), time.Now()
+	}
+	return defaultTable
+}
+
+// Reload discards the cached table so the next Default call re-reads the file
+// and the store
+func Reload() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultTable = nil
+}
@@ -0,0 +1,191 @@
+package fx
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+func TestLookupType(t *testing.T) {
+	table := NewTable([]Rate{
+		{Date: "2024-03-01", Base: "usd", Quote: "ars", Rate: 850},
+		{Date: "2024-03-04", Base: "USD", Quote: "ARS", Type: "MEP", Rate: 1000},
+		{Date: "2024-03-05", Base: "USD", Quote: "ARS", Type: RateOfficial, Rate: 852},
+	})
+
+	tests := []struct {
+		date, rateType, base, quote string
+		want                        Rate
+		ok                          bool
+	}{
+		{"2024-03-05", RateOfficial, "USD", "ARS", Rate{Date: "2024-03-05", Base: "USD", Quote: "ARS", Type: RateOfficial, Rate: 852}, true},
+		// The latest rate on or before the date, timestamps cut to the day
+		{"2024-03-04T15:00:00Z", RateOfficial, "usd", "ars", Rate{Date: "2024-03-01", Base: "USD", Quote: "ARS", Type: RateOfficial, Rate: 850}, true},
+		// The opposite pair is inverted
+		{"2024-03-06", RateMEP, "ARS", "USD", Rate{Date: "2024-03-04", Base: "ARS", Quote: "USD", Type: RateMEP, Rate: 0.001}, true},
+		{"2024-03-06", RateCCL, "USD", "ARS", Rate{}, false},
+		{"2024-02-29", RateOfficial, "USD", "ARS", Rate{}, false},
+		{"2024-03-06", RateOfficial, "USD", "EUR", Rate{}, false},
+		{"2024-03-06", RateCard, "EUR", "eur", Rate{Date: "2024-03-06", Base: "EUR", Quote: "EUR", Type: RateCard, Rate: 1}, true},
+	}
+	for _, tt := range tests {
+		got, ok := table.LookupType(tt.date, tt.rateType, tt.base, tt.quote)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("LookupType(%s, %s, %s, %s) = %+v, %v, want %+v, %v", tt.date, tt.rateType, tt.base, tt.quote, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if _, ok := (*Table)(nil).Lookup("2024-03-05", "USD", "ARS"); ok {
+		t.Error("Lookup on a nil table found a rate")
+	}
+}
+
+func TestLookupMaxStaleDays(t *testing.T) {
+	table := NewTable([]Rate{{Date: "2024-03-01", Base: "USD", Quote: "ARS", Rate: 850}})
+	if _, ok := table.Lookup("2024-03-08", "USD", "ARS"); !ok {
+		t.Errorf("rate %d days old not used", MaxStaleDays)
+	}
+	if _, ok := table.Lookup("2024-03-09", "ARS", "USD"); ok {
+		t.Errorf("rate %d days old used", MaxStaleDays+1)
+	}
+}
+
+func TestNewTableSameDay(t *testing.T) {
+	table := NewTable([]Rate{
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Rate: 850},
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Rate: 855},
+		{Date: "2024-02-29", Base: "USD", Quote: "ARS", Rate: 849},
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Rate: 0},
+	})
+	if r, _ := table.Lookup("2024-03-01", "USD", "ARS"); r.Rate != 855 {
+		t.Errorf("rate = %v, want the last one of the day, 855", r.Rate)
+	}
+	if r, _ := table.Lookup("2024-02-29", "USD", "ARS"); r.Rate != 849 {
+		t.Errorf("rate = %v, want 849", r.Rate)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	table := NewTable([]Rate{
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Rate: 1052.75},
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Type: RateMEP, Rate: 1000},
+	})
+	tests := []struct {
+		amount   money.Amount
+		from, to string
+		rateType string
+		want     money.Amount
+	}{
+		// 123.45 × 1052.75 = 129961.9875
+		{12345, "USD", "ARS", RateOfficial, 12996199},
+		{-12345, "USD", "ARS", RateOfficial, -12996199},
+		// 1500 / 1000 through the inverted pair
+		{150000, "ARS", "USD", RateMEP, 150},
+		// 0.05 / 1000 rounds to zero
+		{5, "ARS", "USD", RateMEP, 0},
+		{999, "ARS", "ARS", RateOfficial, 999},
+	}
+	for _, tt := range tests {
+		got, ok := table.Convert(money.New(tt.amount, tt.from), "2024-03-02", tt.to, tt.rateType)
+		if !ok || got.Amount != tt.want || got.Currency != tt.to || got.RateType != tt.rateType {
+			t.Errorf("Convert(%s %s to %s, %s) = %+v, %v, want %s", tt.amount, tt.from, tt.to, tt.rateType, got, ok, tt.want)
+		}
+	}
+	if got, _ := table.Convert(money.New(12345, "USD"), "2024-03-02", "ARS", RateOfficial); got.RateDate != "2024-03-01" || got.Rate != 1052.75 {
+		t.Errorf("conversion = %+v, want the rate of 2024-03-01", got)
+	}
+	if _, ok := table.Convert(money.New(100, "EUR"), "2024-03-02", "ARS", RateOfficial); ok {
+		t.Error("converted without a rate")
+	}
+
+	// The embedded Money keeps amount and currency at the top level
+	conv, _ := table.Convert(money.New(100, "USD"), "2024-03-02", "ARS", RateOfficial)
+	data, err := json.Marshal(conv)
+	if err != nil || string(data) != `{"amount":"1052.75","currency":"ARS","rate":1052.75,"rate_date":"2024-03-01","rate_type":"official"}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          []Rate
+	}{
+		{
+			name:    "without rate_type",
+			content: "date,base,quote,rate\n2024-03-01, USD, ARS, 850.5\n",
+			want:    []Rate{{Date: "2024-03-01", Base: "USD", Quote: "ARS", Rate: 850.5}},
+		},
+		{
+			name:    "with rate_type",
+			content: "Rate_Type,Date,Base,Quote,Rate\nmep,2024-03-01,USD,ARS,1000\n,2024-03-02,USD,ARS,851\n",
+			want: []Rate{
+				{Date: "2024-03-01", Base: "USD", Quote: "ARS", Type: "mep", Rate: 1000},
+				{Date: "2024-03-02", Base: "USD", Quote: "ARS", Rate: 851},
+			},
+		},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		got, err := readCSV(strings.NewReader(tt.content))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: readCSV = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+
+	for _, content := range []string{
+		"date,base,rate\n2024-03-01,USD,850\n",
+		"date,base,quote,rate\n2024-03-01,USD,ARS,abc\n",
+	} {
+		if got, err := readCSV(strings.NewReader(content)); err == nil {
+			t.Errorf("readCSV(%q) = %+v, want an error", content, got)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rates.CSV")
+	if err := os.WriteFile(path, []byte("date,base,quote,rate,rate_type\n2024-03-01,usd,ars,850,\n2024-03-01,usd,ars,1000,MEP\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Rates without a type get the one given, or official
+	got, err := ReadFile(path, RateCCL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rate{
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Type: RateCCL, Rate: 850},
+		{Date: "2024-03-01", Base: "USD", Quote: "ARS", Type: RateMEP, Rate: 1000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadFile = %+v, want %+v", got, want)
+	}
+
+	jsonPath := filepath.Join(dir, "rates.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"rates": [{"date": "2024-03-01", "base": "USD", "quote": "ARS", "rate": 850}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadFile(jsonPath, ""); err != nil || len(got) != 1 || got[0].Type != RateOfficial {
+		t.Errorf("ReadFile(json) = %+v, %v, want one official rate", got, err)
+	}
+
+	for _, content := range []string{
+		"date,base,quote,rate\n01/03/2024,USD,ARS,850\n",
+		"date,base,quote,rate\n2024-03-01,USD,USD,1\n",
+		"date,base,quote,rate\n2024-03-01,USD,ARS,-1\n",
+		"date,base,quote,rate,rate_type\n2024-03-01,USD,ARS,850,blue\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := ReadFile(path, ""); err == nil {
+			t.Errorf("ReadFile(%q) = %+v, want an error", content, got)
+		}
+	}
+}
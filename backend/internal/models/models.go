@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/money"
 )
 
 type User struct {
@@ -22,23 +23,231 @@ type Upload struct {
 }
 
 type Transaction struct {
-	ID          string    `json:"transaction_id" db:"transaction_id"`
-	UserID      uuid.UUID `json:"user_id" db:"user_id"`
-	UploadID    uuid.UUID `json:"upload_id" db:"upload_id"`
-	Source      string    `json:"source" db:"source"`
-	Account     string    `json:"account" db:"account"`
-	Date        string    `json:"date" db:"date"`
-	Amount      float64   `json:"amount" db:"amount"`
-	Currency    string    `json:"currency" db:"currency"`
-	Description string    `json:"description" db:"description"`
-	Direction   string    `json:"direction" db:"direction"`
-	Merchant    *string   `json:"merchant" db:"merchant"`
-	Category    *string   `json:"category" db:"category"`
-	Subcategory *string   `json:"subcategory" db:"subcategory"`
-	Balance     *float64  `json:"balance" db:"balance"`
-	IsTransfer  bool      `json:"is_transfer" db:"is_transfer"`
-	IsFee       bool      `json:"is_fee" db:"is_fee"`
-	IsTax       bool      `json:"is_tax" db:"is_tax"`
-	Neutralized bool      `json:"neutralized" db:"neutralized"`
-	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
+	ID          string        `json:"transaction_id" db:"transaction_id"`
+	UserID      uuid.UUID     `json:"user_id" db:"user_id"`
+	UploadID    uuid.UUID     `json:"upload_id" db:"upload_id"`
+	Source      string        `json:"source" db:"source"`
+	Account     string        `json:"account" db:"account"`
+	AccountID   *uuid.UUID    `json:"account_id" db:"account_id"` // the user's account the row was imported into
+	Date        string        `json:"date" db:"date"`
+	ValueDate   string        `json:"value_date,omitempty" db:"value_date"`
+	Amount      money.Amount  `json:"amount" db:"amount"`
+	Currency    string        `json:"currency" db:"currency"`
+	Description string        `json:"description" db:"description"`
+	Direction   string        `json:"direction" db:"direction"`
+	Merchant    *string       `json:"merchant" db:"merchant"`
+	RawMerchant *string       `json:"raw_merchant" db:"raw_merchant"` // merchant as extracted by the parser
+	Category    *string       `json:"category" db:"category"`
+	Subcategory *string       `json:"subcategory" db:"subcategory"`
+	Notes       *string       `json:"notes" db:"notes"`
+	Balance     *money.Amount `json:"balance" db:"balance"`
+	IsTransfer  bool          `json:"is_transfer" db:"is_transfer"`
+	IsFee       bool          `json:"is_fee" db:"is_fee"`
+	IsTax       bool          `json:"is_tax" db:"is_tax"`
+	Neutralized bool          `json:"neutralized" db:"neutralized"`
+	ProcessedAt time.Time     `json:"processed_at" db:"processed_at"`
+	// IsCardPayment marks a credit card payment: the debit in the bank account
+	// or the payment credit on the card statement
+	IsCardPayment bool `json:"is_card_payment" db:"is_card_payment"`
+	// DuplicateOf is set when the row is the same movement as another stored
+	// transaction, imported again from an overlapping statement. It is hidden.
+	DuplicateOf *string `json:"duplicate_of,omitempty" db:"duplicate_of"`
+
+	// Set by the learned classifier when no rule matched. Low-confidence
+	// suggestions are not applied and the transaction needs review instead.
+	SuggestedCategory    *string  `json:"suggested_category,omitempty" db:"suggested_category"`
+	SuggestedSubcategory *string  `json:"suggested_subcategory,omitempty" db:"suggested_subcategory"`
+	CategoryConfidence   *float64 `json:"category_confidence,omitempty" db:"category_confidence"`
+	NeedsReview          bool     `json:"needs_review" db:"needs_review"`
+	// Provenance records which stage and rule set the current category
+	Provenance *ClassificationProvenance `json:"provenance,omitempty" db:"provenance"`
+
+	// Fields edited by the user are locked so re-imports and reclassification keep them
+	CategoryLocked    bool `json:"category_locked" db:"category_locked"`
+	SubcategoryLocked bool `json:"subcategory_locked" db:"subcategory_locked"`
+	MerchantLocked    bool `json:"merchant_locked" db:"merchant_locked"`
+}
+
+// ClassificationProvenance explains how a transaction got its category
+type ClassificationProvenance struct {
+	Stage      string     `json:"stage"` // rule, user_rule, parser, model, transfer or user
+	RuleID     *uuid.UUID `json:"rule_id,omitempty"`
+	RuleName   string     `json:"rule_name,omitempty"`
+	Matched    string     `json:"matched,omitempty"` // e.g. keyword=netflix
+	Confidence *float64   `json:"confidence,omitempty"`
+	At         time.Time  `json:"at"`
+	// Replaced is the classification a transfer link overrode, restored when
+	// the link is removed
+	Replaced *ReplacedClassification `json:"replaced,omitempty"`
+}
+
+// ReplacedClassification is a transaction's category before it was neutralized
+type ReplacedClassification struct {
+	Category    *string                   `json:"category"`
+	Subcategory *string                   `json:"subcategory"`
+	Provenance  *ClassificationProvenance `json:"provenance"`
+}
+
+// ClassificationLocked reports whether the user has set the category or subcategory
+func (t Transaction) ClassificationLocked() bool {
+	return t.CategoryLocked || t.SubcategoryLocked
+}
+
+// Money returns the amount of the transaction in its currency
+func (t Transaction) Money() money.Money {
+	return money.New(t.Amount, t.Currency)
+}
+
+// ImportProfile maps the columns of a bank CSV export that has no dedicated parser
+type ImportProfile struct {
+	ID                uuid.UUID `json:"id" db:"id"`
+	UserID            uuid.UUID `json:"user_id" db:"user_id"`
+	Name              string    `json:"name" db:"name"`
+	Separator         string    `json:"separator" db:"separator"`
+	HeaderMarker      string    `json:"header_marker" db:"header_marker"` // text that identifies the header row
+	DateColumn        string    `json:"date_column" db:"date_column"`
+	DateLayout        string    `json:"date_layout" db:"date_layout"` // e.g. DD/MM/YYYY
+	AmountColumn      string    `json:"amount_column,omitempty" db:"amount_column"`
+	DebitColumn       string    `json:"debit_column,omitempty" db:"debit_column"`
+	CreditColumn      string    `json:"credit_column,omitempty" db:"credit_column"`
+	DecimalStyle      string    `json:"decimal_style" db:"decimal_style"` // comma (1.234,56) or dot (1,234.56)
+	DescriptionColumn string    `json:"description_column" db:"description_column"`
+	CurrencyColumn    string    `json:"currency_column,omitempty" db:"currency_column"`
+	Currency          string    `json:"currency,omitempty" db:"currency"` // used when there is no currency column
+	Source            string    `json:"source" db:"source"`
+	Account           string    `json:"account" db:"account"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// ClassificationRule assigns a category to transactions matching all of its conditions.
+// Global rules have a nil UserID. Lower priorities are evaluated first.
+type ClassificationRule struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	UserID      uuid.UUID      `json:"user_id" db:"user_id"`
+	Name        string         `json:"name" db:"name"`
+	Priority    int            `json:"priority" db:"priority"`
+	Conditions  RuleConditions `json:"conditions" db:"conditions"`
+	Category    string         `json:"category" db:"category"`
+	Subcategory string         `json:"subcategory" db:"subcategory"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// RuleConditions are ANDed together; empty conditions always match
+type RuleConditions struct {
+	Merchant  string        `json:"merchant,omitempty"`   // exact merchant, case-insensitive
+	Contains  []string      `json:"contains,omitempty"`   // any of these description substrings
+	Regex     string        `json:"regex,omitempty"`      // description regex, case-insensitive
+	MinAmount *money.Amount `json:"min_amount,omitempty"` // absolute amount
+	MaxAmount *money.Amount `json:"max_amount,omitempty"` // absolute amount
+	Source    string        `json:"source,omitempty"`
+	Account   string        `json:"account,omitempty"`
+	Direction string        `json:"direction,omitempty"` // debit or credit
+}
+
+// Statement holds statement-level data reported by parsers alongside transactions
+type Statement struct {
+	Source         string        `json:"source"`
+	Account        string        `json:"account"`
+	Currency       string        `json:"currency"`
+	OpeningBalance *money.Amount `json:"opening_balance,omitempty"`
+	OpeningDate    string        `json:"opening_date,omitempty"`
+	ClosingBalance *money.Amount `json:"closing_balance,omitempty"`
+	ClosingDate    string        `json:"closing_date,omitempty"`
+}
+
+// MerchantAlias maps a merchant variant to the canonical merchant chosen by the user
+type MerchantAlias struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Alias     string    `json:"alias" db:"alias"`         // e.g. "Payu*ar*uber" or "UBER TRIP"
+	Canonical string    `json:"canonical" db:"canonical"` // e.g. "Uber"
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TransferLink pairs the debit and credit sides of an internal transfer or of a
+// credit card payment.
+// Links removed by the user are kept as "rejected" so they are not matched again.
+type TransferLink struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	DebitID    string    `json:"debit_id" db:"debit_id"`
+	CreditID   string    `json:"credit_id" db:"credit_id"`
+	Kind       string    `json:"kind" db:"kind"`     // transfer or card_payment
+	Method     string    `json:"method" db:"method"` // auto, manual or rejected
+	Score      float64   `json:"score" db:"score"`   // 0-1, how well amounts and dates agree
+	FXRate     *float64  `json:"fx_rate,omitempty" db:"fx_rate"`
+	FXRateDate string    `json:"fx_rate_date,omitempty" db:"fx_rate_date"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+
+	// Accounts of the two sides, the user's account ID or source/account, so
+	// the account pair is known without loading the transactions
+	DebitAccount  string `json:"debit_account,omitempty" db:"debit_account"`
+	CreditAccount string `json:"credit_account,omitempty" db:"credit_account"`
+}
+
+// Transfer link methods
+const (
+	LinkAuto     = "auto"
+	LinkManual   = "manual"
+	LinkRejected = "rejected"
+)
+
+// Transfer link kinds
+const (
+	KindTransfer    = "transfer"     // money moved between two of the user's accounts
+	KindCardPayment = "card_payment" // a bank debit settling a credit card statement
+)
+
+// TransferSettings overrides how a user's transfers are matched. Nil fields
+// use the server defaults.
+type TransferSettings struct {
+	UserID          uuid.UUID `json:"-" db:"user_id"`
+	AmountTolerance *float64  `json:"amount_tolerance" db:"amount_tolerance"` // relative, e.g. 0.005 for ±0.5%
+	FXSpread        *float64  `json:"fx_spread" db:"fx_spread"`               // relative, for cross-currency pairs
+	DaysBefore      *int      `json:"days_before" db:"days_before"`           // days the credit may precede the debit
+	DaysAfter       *int      `json:"days_after" db:"days_after"`             // days the credit may follow the debit
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DuplicateCandidate pairs a stored transaction with a row from an overlapping
+// statement that looks like the same movement
+type DuplicateCandidate struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	TransactionID string     `json:"transaction_id" db:"transaction_id"` // the transaction kept
+	DuplicateID   string     `json:"duplicate_id" db:"duplicate_id"`     // the row hidden when merged
+	Score         float64    `json:"score" db:"score"`                   // description similarity, 0-1
+	Status        string     `json:"status" db:"status"`                 // pending, merged or dismissed
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// Duplicate candidate statuses
+const (
+	DuplicatePending   = "pending"
+	DuplicateMerged    = "merged"
+	DuplicateDismissed = "dismissed"
+)
+
+// Account is one of the user's bank accounts, cards or wallets. Imported rows
+// are assigned to the account whose institution and parser account match the
+// Source and Account the parser produced.
+type Account struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Institution   string    `json:"institution" db:"institution"`       // parser source, e.g. brubank
+	ParserAccount string    `json:"parser_account" db:"parser_account"` // parser account, e.g. caja_ahorro_pesos
+	Type          string    `json:"type" db:"type"`                     // savings, checking, credit_card or wallet
+	Currency      string    `json:"currency" db:"currency"`
+	Name          string    `json:"name" db:"name"`
+	Number        *string   `json:"number" db:"number"` // masked, e.g. ****1234
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 }
+
+// Account types
+const (
+	AccountSavings    = "savings"
+	AccountChecking   = "checking"
+	AccountCreditCard = "credit_card"
+	AccountWallet     = "wallet"
+)
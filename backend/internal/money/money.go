@@ -0,0 +1,228 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is an exact decimal with two fraction digits, held as a count of
+// cents. It matches the DECIMAL(15, 2) columns and is serialized as a string
+// such as "-1234.56" so JSON clients never see float rounding.
+type Amount int64
+
+// Money is an amount in a currency
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// ErrCurrencyMismatch is returned when adding amounts in different currencies
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// Totals sums amounts per currency code, so amounts in different currencies
+// are never added together
+type Totals map[string]Amount
+
+// Parse reads a plain decimal such as "1234.56", "-0.5" or "+12". More than two
+// fraction digits are rounded half away from zero.
+func Parse(input string) (Amount, error) {
+	s := strings.TrimSpace(input)
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || !digitsOnly(whole) || !digitsOnly(frac) {
+		return 0, fmt.Errorf("money: invalid amount %q", input)
+	}
+
+	cents := int64(0)
+	if whole != "" {
+		n, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil || n > math.MaxInt64/100 {
+			return 0, fmt.Errorf("money: amount %q out of range", input)
+		}
+		cents = n * 100
+	}
+	padded := frac + "00"
+	cents += int64(padded[0]-'0')*10 + int64(padded[1]-'0')
+	if len(frac) > 2 && frac[2] >= '5' {
+		cents++
+	}
+	if neg {
+		cents = -cents
+	}
+	return Amount(cents), nil
+}
+
+// MustParse is like Parse but panics on invalid input; for literals
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// FromCents builds an amount from a count of cents
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// FromFloat rounds f to the nearest cent. Use it only for values that are
+// already floats, such as an amount converted with an exchange rate.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * 100))
+}
+
+// Cents returns the amount as a count of cents
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Float64 returns the amount as a float, for ratios and scores
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// Abs returns the absolute value of a
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Neg returns -a
+func (a Amount) Neg() Amount {
+	return -a
+}
+
+// Sign returns -1, 0 or 1
+func (a Amount) Sign() int {
+	switch {
+	case a < 0:
+		return -1
+	case a > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether a is zero
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// Mul multiplies a by a factor such as an exchange rate, rounding to the cent
+func (a Amount) Mul(factor float64) Amount {
+	return FromFloat(float64(a) * factor / 100)
+}
+
+// String formats a with two fraction digits, e.g. "-1234.56"
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON writes the amount as a decimal string
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number, read exactly
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	s := strings.Trim(string(data), `"`)
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("money: invalid amount %s", data)
+		}
+		*a = FromFloat(f)
+		return nil
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value stores the amount in a DECIMAL column
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads a DECIMAL column
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return a.set(string(v))
+	case string:
+		return a.set(v)
+	case int64:
+		*a = Amount(v * 100)
+	case float64:
+		*a = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (a *Amount) set(s string) error {
+	parsed, err := Parse(s)
+	if err == nil {
+		*a = parsed
+	}
+	return err
+}
+
+// New returns amount in currency
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Add sums two amounts in the same currency
+func (m Money) Add(o Money) (Money, error) {
+	if !strings.EqualFold(m.Currency, o.Currency) {
+		return m, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// String formats m as "1234.56 ARS"
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// Add adds m to the total of its currency
+func (t Totals) Add(m Money) {
+	t[strings.ToUpper(m.Currency)] += m.Amount
+}
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Amount
+	}{
+		{"1234.56", 123456},
+		{"0", 0},
+		{"12", 1200},
+		{"12.5", 1250},
+		{".5", 50},
+		{"7.", 700},
+		{" 42.10 ", 4210},
+		// Signs
+		{"-1234.56", -123456},
+		{"+12", 1200},
+		{"-0.5", -50},
+		{"-0", 0},
+		// More than two fraction digits round half away from zero
+		{"1.004", 100},
+		{"1.005", 101},
+		{"0.995", 100},
+		{"-1.005", -101},
+		{"-0.004", 0},
+		{"2.34999", 235},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	// Thousands separators and decimal commas are the parsers' job, e.g.
+	// common.CleanAmount; Parse only reads plain decimals
+	for _, input := range []string{"", "-", ".", "1,234.56", "12,50", "1.234,56", "1.2.3", "--1", "+-1", "1e3", "abc", "12 ARS", "$12", "99999999999999999999"} {
+		if got, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", input, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, tt := range []struct {
+		a    Amount
+		want string
+	}{
+		{123456, "1234.56"},
+		{-123456, "-1234.56"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{0, "0.00"},
+	} {
+		if got := tt.a.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.a), got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Amount
+	}{
+		{`"1234.56"`, 123456},
+		{`"-0.05"`, -5},
+		{`1234.56`, 123456},
+		{`-12`, -1200},
+		{`0.125`, 13},
+		{`1.5e3`, 150000},
+		{`"2E-2"`, 2},
+	}
+	for _, tt := range tests {
+		var got Amount
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{`"1,234.56"`, `"abc"`, `true`, `"1eX"`} {
+		var got Amount
+		if err := json.Unmarshal([]byte(input), &got); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", input, got)
+		}
+	}
+
+	// null leaves the amount as it was, and a pointer nil
+	got := Amount(700)
+	if err := json.Unmarshal([]byte(`null`), &got); err != nil || got != 700 {
+		t.Errorf("Unmarshal(null) = %s, %v, want 7.00 unchanged", got, err)
+	}
+	var ptr *Amount
+	if err := json.Unmarshal([]byte(`null`), &ptr); err != nil || ptr != nil {
+		t.Errorf("Unmarshal(null) into *Amount = %v, %v, want nil", ptr, err)
+	}
+
+	// Amounts round-trip as strings
+	raw, err := json.Marshal(struct {
+		Amount Amount `json:"amount"`
+	}{-123456})
+	if err != nil || string(raw) != `{"amount":"-1234.56"}` {
+		t.Errorf("Marshal = %s, %v", raw, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want Amount
+	}{
+		{[]byte("1234.56"), 123456},
+		{[]byte("-0.50"), -50},
+		{"15.00", 1500},
+		{int64(42), 4200},
+		{int64(-3), -300},
+		{12.34, 1234},
+		{-0.1, -10},
+	}
+	for _, tt := range tests {
+		var got Amount
+		if err := got.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%#v): %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Scan(%#v) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+
+	for _, src := range []interface{}{nil, true, []byte("1,5"), "abc"} {
+		var got Amount
+		if err := got.Scan(src); err == nil {
+			t.Errorf("Scan(%#v) = %s, want an error", src, got)
+		}
+	}
+
+	// Value writes what Scan reads
+	v, err := Amount(-123456).Value()
+	if err != nil || v != "-1234.56" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	m := New(123456, "ars")
+	if m.Currency != "ARS" || m.String() != "1234.56 ARS" {
+		t.Errorf("New = %+v (%s)", m, m)
+	}
+
+	sum, err := m.Add(New(-456, "ARS"))
+	if err != nil || sum != New(123000, "ARS") {
+		t.Errorf("Add = %s, %v, want 1230.00 ARS", sum, err)
+	}
+	if got, err := m.Add(New(100, "USD")); !errors.Is(err, ErrCurrencyMismatch) || got != m {
+		t.Errorf("Add across currencies = %s, %v, want %s and ErrCurrencyMismatch", got, err, m)
+	}
+
+	data, err := json.Marshal(New(-50, "USD"))
+	if err != nil || string(data) != `{"amount":"-0.50","currency":"USD"}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+}
+
+func TestTotals(t *testing.T) {
+	totals := make(Totals)
+	totals.Add(New(1000, "ARS"))
+	totals.Add(New(250, "usd"))
+	totals.Add(New(-300, "ars"))
+	totals.Add(New(50, "USD"))
+
+	if len(totals) != 2 || totals["ARS"] != 700 || totals["USD"] != 300 {
+		t.Errorf("totals = %v, want ARS 7.00 and USD 3.00", totals)
+	}
+}
@@ -0,0 +1,139 @@
+package processor
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+// ResolveAccounts assigns each transaction to one of the user's accounts.
+//
+// When accountID is set, every row goes to that account, e.g. the second of two
+// Brubank accounts that the same parser reads alike. Rows whose parser account
+// is not the one the account is registered with get IDs namespaced by the
+// account, so the same statement imported into two accounts gives distinct
+// transactions.
+//
+// Otherwise rows go to the account matching their Source and Account, which is
+// created the first time a statement of that account is imported.
+func ResolveAccounts(userID, accountID uuid.UUID, txs []models.Transaction) ([]models.Account, error) {
+	if accountID != uuid.Nil {
+		account, err := db.GetDB().GetAccount(userID, accountID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range txs {
+			if txs[i].Source != account.Institution || txs[i].Account != account.ParserAccount {
+				txs[i].ID = common.GenerateID(txs[i].ID, account.ID.String(), "", "", "")
+			}
+			txs[i].AccountID = &account.ID
+		}
+		return []models.Account{account}, nil
+	}
+
+	type key struct{ institution, parserAccount string }
+	known := make(map[key]models.Account)
+	for _, a := range db.GetDB().GetAccounts(userID) {
+		known[key{a.Institution, a.ParserAccount}] = a
+	}
+
+	var used []models.Account
+	for i := range txs {
+		k := key{txs[i].Source, txs[i].Account}
+		account, ok := known[k]
+		switch {
+		case !ok:
+			account = newAccount(userID, txs[i])
+			if err := db.GetDB().CreateAccount(account); err == db.ErrConflict {
+				// Created by a concurrent import
+				for _, a := range db.GetDB().GetAccounts(userID) {
+					if a.Institution == k.institution && a.ParserAccount == k.parserAccount {
+						account = a
+					}
+				}
+			} else if err != nil {
+				return nil, err
+			}
+			known[k] = account
+			used = append(used, account)
+		case !accountUsed(used, account.ID):
+			used = append(used, account)
+		}
+		id := account.ID
+		txs[i].AccountID = &id
+	}
+	return used, nil
+}
+
+func accountUsed(accounts []models.Account, id uuid.UUID) bool {
+	for _, a := range accounts {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// newAccount describes the account a transaction was read from, guessing its
+// type from the parser's account name
+func newAccount(userID uuid.UUID, tx models.Transaction) models.Account {
+	account := models.Account{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Institution:   tx.Source,
+		ParserAccount: tx.Account,
+		Type:          AccountTypeOf(tx.Source, tx.Account),
+		Currency:      strings.ToUpper(tx.Currency),
+		Name:          strings.TrimSpace(tx.Source + " " + strings.ReplaceAll(tx.Account, "_", " ")),
+		CreatedAt:     time.Now(),
+	}
+	if number := MaskAccountNumber(tx.Account); number != "" {
+		account.Number = &number
+	}
+	return account
+}
+
+// AccountTypeOf guesses the account type from a parser source and account,
+// such as "santander"/"credito_visa" or "mercadopago"/"cuenta_digital"
+func AccountTypeOf(source, account string) string {
+	name := strings.ToLower(source + " " + account)
+	switch {
+	case containsAny(name, "credito", "crédito", "visa", "master", "amex", "tarjeta", "card"):
+		return models.AccountCreditCard
+	case containsAny(name, "mercadopago", "deel", "digital", "balance", "wallet", "billetera"):
+		return models.AccountWallet
+	case containsAny(name, "ahorro", "savings"):
+		return models.AccountSavings
+	}
+	return models.AccountChecking
+}
+
+func containsAny(s string, words ...string) bool {
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskAccountNumber keeps the last four digits of an account number, e.g.
+// "4001234-5 123-4" becomes "****1234". It returns "" when there are fewer
+// than four digits.
+func MaskAccountNumber(number string) string {
+	var digits []rune
+	for _, r := range number {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 4 {
+		return ""
+	}
+	return "****" + string(digits[len(digits)-4:])
+}
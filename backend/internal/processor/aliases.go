@@ -0,0 +1,25 @@
+package processor
+
+import (
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/merchants"
+)
+
+// ApplyMerchantAliases re-resolves the canonical merchant of a user's stored
+// transactions after their alias table changed. It returns how many changed.
+func ApplyMerchantAliases(userID uuid.UUID) (int, error) {
+	aliases := merchants.NewAliases(db.GetDB().GetMerchantAliases(userID))
+
+	var changed []models.Transaction
+	for _, tx := range db.GetDB().GetTransactions(userID) {
+		if aliases.Apply(&tx) {
+			changed = append(changed, tx)
+		}
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	return len(changed), db.GetDB().UpdateTransactionMerchants(userID, changed)
+}
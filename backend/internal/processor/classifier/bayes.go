@@ -0,0 +1,201 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+const (
+	// ApplyThreshold is the confidence a suggestion needs to be applied automatically
+	ApplyThreshold = 0.8
+	// MinTrainingSize is the number of labelled transactions needed before suggesting
+	MinTrainingSize = 10
+	// correctionWeight counts user-edited categories more than rule-assigned ones
+	correctionWeight = 3
+)
+
+// Model is a multinomial naive Bayes classifier over description tokens,
+// merchant, direction and amount magnitude, trained on one user's transactions
+type Model struct {
+	docs        map[string]float64            // label -> weighted document count
+	tokens      map[string]map[string]float64 // label -> token -> weighted count
+	totals      map[string]float64            // label -> total weighted tokens
+	vocabulary  map[string]bool
+	trainedDocs float64
+	examples    int
+}
+
+// Suggestion is the most likely category for a transaction
+type Suggestion struct {
+	Category    string
+	Subcategory string
+	Confidence  float64
+}
+
+// Train builds a model from the labelled transactions in txs. Neutralized
+// transfers are ignored because their category is not chosen by the user,
+// merged duplicates because they would count a movement twice, and categories
+// the model applied itself unless the user kept them by locking, so its
+// guesses do not reinforce the next model.
+func Train(txs []models.Transaction) *Model {
+	m := &Model{
+		docs:       make(map[string]float64),
+		tokens:     make(map[string]map[string]float64),
+		totals:     make(map[string]float64),
+		vocabulary: make(map[string]bool),
+	}
+	for _, tx := range txs {
+		if tx.Category == nil || *tx.Category == "" || tx.Neutralized || tx.DuplicateOf != nil {
+			continue
+		}
+		if tx.Provenance != nil && tx.Provenance.Stage == StageModel && !tx.ClassificationLocked() {
+			continue
+		}
+		weight := 1.0
+		if tx.ClassificationLocked() {
+			weight = correctionWeight
+		}
+
+		label := labelOf(tx.Category, tx.Subcategory)
+		if m.tokens[label] == nil {
+			m.tokens[label] = make(map[string]float64)
+		}
+		m.docs[label] += weight
+		m.trainedDocs += weight
+		m.examples++
+		for _, f := range features(&tx) {
+			m.tokens[label][f] += weight
+			m.totals[label] += weight
+			m.vocabulary[f] = true
+		}
+	}
+	return m
+}
+
+// Suggest returns the most likely category for tx, or nil when the model has
+// too little training data or nothing in tx resembles the training data
+func (m *Model) Suggest(tx *models.Transaction) *Suggestion {
+	if m.examples < MinTrainingSize || len(m.docs) == 0 {
+		return nil
+	}
+	// Only features seen in training carry evidence, and at least one of them
+	// must come from the description or merchant
+	var feats []string
+	informative := false
+	for _, f := range features(tx) {
+		if m.vocabulary[f] {
+			feats = append(feats, f)
+			informative = informative || strings.HasPrefix(f, "w:") || strings.HasPrefix(f, "m:")
+		}
+	}
+	if !informative {
+		return nil
+	}
+
+	labels := make([]string, 0, len(m.docs))
+	for label := range m.docs {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	// Log posterior per label with Laplace smoothing
+	vocab := float64(len(m.vocabulary))
+	scores := make([]float64, len(labels))
+	best := 0
+	for i, label := range labels {
+		score := math.Log(m.docs[label] / m.trainedDocs)
+		for _, f := range feats {
+			score += math.Log((m.tokens[label][f] + 1) / (m.totals[label] + vocab))
+		}
+		scores[i] = score
+		if score > scores[best] {
+			best = i
+		}
+	}
+
+	// Normalise into a probability for the winning label
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - scores[best])
+	}
+
+	cat, sub := splitLabel(labels[best])
+	return &Suggestion{Category: cat, Subcategory: sub, Confidence: math.Round(100/sum) / 100}
+}
+
+// Fallback classifies tx with the model when no rule gave it a category.
+// Confident suggestions are applied; the rest are stored as suggestions and
+// the transaction is flagged for review. It reports whether tx was changed.
+func (m *Model) Fallback(tx *models.Transaction) bool {
+	if tx.Category != nil || tx.Neutralized {
+		return false
+	}
+	s := m.Suggest(tx)
+	if s == nil {
+		return false
+	}
+
+	cat, sub := s.Category, s.Subcategory
+	var subPtr *string
+	if sub != "" {
+		subPtr = &sub
+	}
+	confidence := s.Confidence
+	tx.CategoryConfidence = &confidence
+	if confidence >= ApplyThreshold {
+		tx.Category, tx.Subcategory = &cat, subPtr
+		tx.Provenance = Provenance(StageModel, "")
+		tx.Provenance.Confidence = &confidence
+		return true
+	}
+	tx.SuggestedCategory, tx.SuggestedSubcategory = &cat, subPtr
+	tx.NeedsReview = true
+	return true
+}
+
+// features turns a transaction into the tokens the model counts
+func features(tx *models.Transaction) []string {
+	var feats []string
+	words := strings.FieldsFunc(strings.ToLower(tx.Description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		if len([]rune(w)) >= 3 {
+			feats = append(feats, "w:"+w)
+		}
+	}
+	if tx.Merchant != nil && strings.TrimSpace(*tx.Merchant) != "" {
+		feats = append(feats, "m:"+strings.ToLower(strings.TrimSpace(*tx.Merchant)))
+	}
+	if len(feats) == 0 {
+		return nil
+	}
+	if tx.Direction != "" {
+		feats = append(feats, "d:"+tx.Direction)
+	}
+	// Order of magnitude of the amount, e.g. a:3 for 1000-9999
+	if abs := tx.Amount.Abs().Float64(); abs >= 1 {
+		feats = append(feats, "a:"+strconv.Itoa(int(math.Log10(abs))))
+	}
+	return feats
+}
+
+func labelOf(category, subcategory *string) string {
+	label := *category
+	if subcategory != nil {
+		label += "\x00" + *subcategory
+	}
+	return label
+}
+
+func splitLabel(label string) (string, string) {
+	if i := strings.IndexByte(label, 0); i >= 0 {
+		return label[:i], label[i+1:]
+	}
+	return label, ""
+}
@@ -0,0 +1,128 @@
+package classifier
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// labelled returns n debits described as description and categorized as
+// category by a rule
+func labelled(n int, description, category string) []models.Transaction {
+	txs := make([]models.Transaction, n)
+	for i := range txs {
+		cat := category
+		txs[i] = models.Transaction{
+			ID:          fmt.Sprintf("%s-%d", category, i),
+			Description: description,
+			Direction:   "debit",
+			Amount:      money.Amount(-250000),
+			Category:    &cat,
+			Provenance:  Provenance(StageRule, "keyword"),
+		}
+	}
+	return txs
+}
+
+func unlabelled(description string) models.Transaction {
+	return models.Transaction{ID: "new", Description: description, Direction: "debit", Amount: money.Amount(-250000)}
+}
+
+func TestTrainMinTrainingSize(t *testing.T) {
+	tx := unlabelled("NETFLIX")
+	if s := Train(labelled(MinTrainingSize-1, "NETFLIX", "entretenimiento")).Suggest(&tx); s != nil {
+		t.Errorf("suggestion with %d examples = %+v, want none", MinTrainingSize-1, s)
+	}
+	if s := Train(labelled(MinTrainingSize, "NETFLIX", "entretenimiento")).Suggest(&tx); s == nil || s.Category != "entretenimiento" {
+		t.Errorf("suggestion with %d examples = %+v, want entretenimiento", MinTrainingSize, s)
+	}
+	other := unlabelled("ALQUILER")
+	if s := Train(labelled(MinTrainingSize, "NETFLIX", "entretenimiento")).Suggest(&other); s != nil {
+		t.Errorf("suggestion for unseen words = %+v, want none", s)
+	}
+}
+
+// A user correction counts correctionWeight times a rule-assigned category
+func TestTrainCorrectionWeight(t *testing.T) {
+	txs := func(locked bool) []models.Transaction {
+		txs := labelled(5, "UBER", "transporte")
+		corrections := labelled(2, "UBER", "comida")
+		for i := range corrections {
+			corrections[i].CategoryLocked = locked
+		}
+		return append(append(txs, corrections...), labelled(3, "SUPERMERCADO COTO", "supermercado")...)
+	}
+	tx := unlabelled("UBER")
+	if s := Train(txs(false)).Suggest(&tx); s == nil || s.Category != "transporte" {
+		t.Errorf("suggestion without corrections = %+v, want transporte", s)
+	}
+	if s := Train(txs(true)).Suggest(&tx); s == nil || s.Category != "comida" {
+		t.Errorf("suggestion with corrections = %+v, want comida", s)
+	}
+}
+
+// Categories the model applied do not train the next model unless the user
+// locked them
+func TestTrainSkipsModelCategories(t *testing.T) {
+	txs := labelled(MinTrainingSize, "NETFLIX", "entretenimiento")
+	for i := range txs {
+		txs[i].Provenance = Provenance(StageModel, "")
+	}
+	tx := unlabelled("NETFLIX")
+	if s := Train(txs).Suggest(&tx); s != nil {
+		t.Errorf("suggestion from model categories = %+v, want none", s)
+	}
+	for i := range txs {
+		txs[i].CategoryLocked = true
+	}
+	if s := Train(txs).Suggest(&tx); s == nil || s.Category != "entretenimiento" {
+		t.Errorf("suggestion from locked model categories = %+v, want entretenimiento", s)
+	}
+}
+
+func TestFallbackApplyThreshold(t *testing.T) {
+	// Every NETFLIX is entretenimiento: the suggestion is applied
+	model := Train(append(labelled(5, "NETFLIX", "entretenimiento"), labelled(5, "SUPERMERCADO COTO", "supermercado")...))
+	tx := unlabelled("NETFLIX")
+	if !model.Fallback(&tx) {
+		t.Fatal("Fallback did not change the transaction")
+	}
+	if tx.Category == nil || *tx.Category != "entretenimiento" || tx.NeedsReview {
+		t.Errorf("category = %v, needs review = %v, want entretenimiento applied", tx.Category, tx.NeedsReview)
+	}
+	if tx.CategoryConfidence == nil || *tx.CategoryConfidence < ApplyThreshold || tx.Provenance == nil || tx.Provenance.Stage != StageModel {
+		t.Errorf("confidence = %v, provenance = %+v", tx.CategoryConfidence, tx.Provenance)
+	}
+
+	// UBER is split evenly between two categories: it is only suggested
+	model = Train(append(labelled(5, "UBER", "transporte"), labelled(5, "UBER", "comida")...))
+	tx = unlabelled("UBER")
+	if !model.Fallback(&tx) {
+		t.Fatal("Fallback did not change the transaction")
+	}
+	if tx.Category != nil || !tx.NeedsReview || tx.SuggestedCategory == nil {
+		t.Errorf("category = %v, suggested = %v, needs review = %v, want a suggestion to review", tx.Category, tx.SuggestedCategory, tx.NeedsReview)
+	}
+	if tx.CategoryConfidence == nil || *tx.CategoryConfidence >= ApplyThreshold {
+		t.Errorf("confidence = %v, want below %v", tx.CategoryConfidence, ApplyThreshold)
+	}
+}
+
+func TestFallbackSkipsClassifiedAndNeutralized(t *testing.T) {
+	model := Train(labelled(MinTrainingSize, "NETFLIX", "entretenimiento"))
+
+	cat := "suscripciones"
+	categorized := unlabelled("NETFLIX")
+	categorized.Category = &cat
+	if model.Fallback(&categorized) || *categorized.Category != cat || categorized.CategoryConfidence != nil {
+		t.Errorf("categorized row changed to %v", *categorized.Category)
+	}
+
+	neutralized := unlabelled("NETFLIX")
+	neutralized.Neutralized = true
+	if model.Fallback(&neutralized) || neutralized.Category != nil || neutralized.SuggestedCategory != nil {
+		t.Errorf("neutralized row changed: %v, %v", neutralized.Category, neutralized.SuggestedCategory)
+	}
+}
@@ -0,0 +1,185 @@
+package classifier
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/merchants"
+)
+
+// Engine evaluates classification rules in order and applies the first match
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule     models.ClassificationRule
+	merchant string // merchants.Key of the merchant condition
+	contains []string
+	regex    *regexp.Regexp
+}
+
+// Match describes the rule that classified a transaction and what it matched on
+type Match struct {
+	Rule    models.ClassificationRule
+	Matched string
+}
+
+// New compiles rules into an engine, ordered by ascending priority
+func New(rules []models.ClassificationRule) (*Engine, error) {
+	return (&Engine{}).Extend(rules)
+}
+
+// Extend returns a new engine that evaluates rules before the rules of e.
+// It is used to layer a user's own rules on top of the global ones.
+func (e *Engine) Extend(rules []models.ClassificationRule) (*Engine, error) {
+	compiled := make([]compiledRule, 0, len(rules)+len(e.rules))
+	for _, r := range rules {
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, c)
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].rule.Priority < compiled[j].rule.Priority
+	})
+	return &Engine{rules: append(compiled, e.rules...)}, nil
+}
+
+// Rules returns the rules of the engine in evaluation order
+func (e *Engine) Rules() []models.ClassificationRule {
+	rules := make([]models.ClassificationRule, len(e.rules))
+	for i, c := range e.rules {
+		rules[i] = c.rule
+	}
+	return rules
+}
+
+func compile(r models.ClassificationRule) (compiledRule, error) {
+	if r.Category == "" {
+		return compiledRule{}, fmt.Errorf("rule %q has no category", r.Name)
+	}
+	c := compiledRule{rule: r, merchant: merchants.Key(r.Conditions.Merchant)}
+	for _, kw := range r.Conditions.Contains {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+			c.contains = append(c.contains, kw)
+		}
+	}
+	if r.Conditions.Regex != "" {
+		re, err := regexp.Compile("(?i)" + r.Conditions.Regex)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("rule %q: invalid regex: %w", r.Name, err)
+		}
+		c.regex = re
+	}
+	return c, nil
+}
+
+// Match returns the first rule whose conditions all hold for tx, or nil
+func (e *Engine) Match(tx *models.Transaction) *Match {
+	for _, c := range e.rules {
+		if matched, ok := c.match(tx); ok {
+			return &Match{Rule: c.rule, Matched: matched}
+		}
+	}
+	return nil
+}
+
+// Classify sets the category, subcategory and provenance of tx from the first
+// matching rule. It reports whether a rule matched; unmatched transactions are
+// left untouched.
+func (e *Engine) Classify(tx *models.Transaction) bool {
+	m := e.Match(tx)
+	if m == nil {
+		return false
+	}
+	cat, sub := m.Rule.Category, m.Rule.Subcategory
+	tx.Category = &cat
+	tx.Subcategory = nil
+	if sub != "" {
+		tx.Subcategory = &sub
+	}
+	tx.Provenance = m.Provenance()
+	return true
+}
+
+// match checks every condition and returns a short description of what matched
+func (c compiledRule) match(tx *models.Transaction) (string, bool) {
+	cond := c.rule.Conditions
+	var matched []string
+
+	if cond.Merchant != "" {
+		if !c.matchesMerchant(tx.Merchant) && !c.matchesMerchant(tx.RawMerchant) {
+			return "", false
+		}
+		matched = append(matched, "merchant="+cond.Merchant)
+	}
+
+	if len(c.contains) > 0 {
+		desc := strings.ToLower(tx.Description)
+		found := ""
+		for _, kw := range c.contains {
+			if strings.Contains(desc, kw) {
+				found = kw
+				break
+			}
+		}
+		if found == "" {
+			return "", false
+		}
+		matched = append(matched, "keyword="+found)
+	}
+
+	if c.regex != nil {
+		m := c.regex.FindString(tx.Description)
+		if m == "" {
+			return "", false
+		}
+		matched = append(matched, "regex="+m)
+	}
+
+	abs := tx.Amount.Abs()
+	if cond.MinAmount != nil {
+		if abs < *cond.MinAmount {
+			return "", false
+		}
+		matched = append(matched, "amount>="+cond.MinAmount.String())
+	}
+	if cond.MaxAmount != nil {
+		if abs > *cond.MaxAmount {
+			return "", false
+		}
+		matched = append(matched, "amount<="+cond.MaxAmount.String())
+	}
+
+	if cond.Source != "" {
+		if !strings.EqualFold(tx.Source, cond.Source) {
+			return "", false
+		}
+		matched = append(matched, "source="+cond.Source)
+	}
+	if cond.Account != "" {
+		if !strings.EqualFold(tx.Account, cond.Account) {
+			return "", false
+		}
+		matched = append(matched, "account="+cond.Account)
+	}
+	if cond.Direction != "" {
+		if tx.Direction != cond.Direction {
+			return "", false
+		}
+		matched = append(matched, "direction="+cond.Direction)
+	}
+
+	return strings.Join(matched, ", "), true
+}
+
+// matchesMerchant compares merchants by their normalized key, so a rule for
+// "Payu*ar*uber" also matches the canonical "Uber"
+func (c compiledRule) matchesMerchant(merchant *string) bool {
+	return merchant != nil && c.merchant != "" && merchants.Key(*merchant) == c.merchant
+}
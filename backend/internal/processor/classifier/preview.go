@@ -0,0 +1,85 @@
+package classifier
+
+import (
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// Change describes a transaction whose category a rule would change
+type Change struct {
+	TransactionID   string       `json:"transaction_id"`
+	Date            string       `json:"date"`
+	Description     string       `json:"description"`
+	Amount          money.Amount `json:"amount"`
+	FromCategory    *string      `json:"from_category"`
+	FromSubcategory *string      `json:"from_subcategory"`
+	ToCategory      *string      `json:"to_category"`
+	ToSubcategory   *string      `json:"to_subcategory"`
+}
+
+// PreviewResult summarises a dry run of a draft rule
+type PreviewResult struct {
+	Matched int      `json:"matched"`
+	Changes []Change `json:"changes"`
+}
+
+// Preview evaluates a draft rule together with the user's rules against txs
+// without saving anything. A draft carrying the ID of an existing user rule
+// replaces it. Only transactions that the draft itself classifies are reported,
+// and neutralized transfers and user-locked categories are skipped because
+// rules never change them.
+func Preview(userRules []models.ClassificationRule, draft models.ClassificationRule, txs []models.Transaction) (PreviewResult, error) {
+	if draft.ID == uuid.Nil {
+		draft.ID = uuid.New()
+	}
+
+	rules := make([]models.ClassificationRule, 0, len(userRules)+1)
+	for _, r := range userRules {
+		if r.ID != draft.ID {
+			rules = append(rules, r)
+		}
+	}
+	rules = append(rules, draft)
+
+	engine, err := Default().Extend(rules)
+	if err != nil {
+		return PreviewResult{}, err
+	}
+
+	result := PreviewResult{Changes: []Change{}}
+	for _, tx := range txs {
+		if tx.Neutralized || tx.ClassificationLocked() {
+			continue
+		}
+		m := engine.Match(&tx)
+		if m == nil || m.Rule.ID != draft.ID {
+			continue
+		}
+		result.Matched++
+
+		next := tx
+		engine.Classify(&next)
+		if sameString(tx.Category, next.Category) && sameString(tx.Subcategory, next.Subcategory) {
+			continue
+		}
+		result.Changes = append(result.Changes, Change{
+			TransactionID:   tx.ID,
+			Date:            tx.Date,
+			Description:     tx.Description,
+			Amount:          tx.Amount,
+			FromCategory:    tx.Category,
+			FromSubcategory: tx.Subcategory,
+			ToCategory:      next.Category,
+			ToSubcategory:   next.Subcategory,
+		})
+	}
+	return result, nil
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
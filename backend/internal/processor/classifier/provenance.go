@@ -0,0 +1,36 @@
+package classifier
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+// Stages of the classification pipeline recorded as provenance
+const (
+	StageRule     = "rule"      // global rule from the rules file, the database or the built-in table
+	StageUserRule = "user_rule" // one of the user's own rules
+	StageParser   = "parser"    // parser-specific logic, e.g. Deel client payments
+	StageModel    = "model"     // learned classifier
+	StageTransfer = "transfer"  // internal transfer neutralization
+	StageUser     = "user"      // manual edit
+)
+
+// Provenance records that stage set a transaction's category because of matched
+func Provenance(stage, matched string) *models.ClassificationProvenance {
+	return &models.ClassificationProvenance{Stage: stage, Matched: matched, At: time.Now()}
+}
+
+// Provenance records the rule behind a match
+func (m *Match) Provenance() *models.ClassificationProvenance {
+	stage := StageRule
+	if m.Rule.UserID != uuid.Nil {
+		stage = StageUserRule
+	}
+	p := Provenance(stage, m.Matched)
+	id := m.Rule.ID
+	p.RuleID = &id
+	p.RuleName = m.Rule.Name
+	return p
+}
@@ -0,0 +1,185 @@
+package classifier
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+// Priority bands for global rules; user rules are always evaluated first
+const (
+	MerchantPriority = 100
+	KeywordPriority  = 200
+	BuiltinPriority  = 1000
+)
+
+// DefaultRulesPath is used when CLASSIFICATION_RULES is not set
+const DefaultRulesPath = "classification_rules.json"
+
+// ruleNamespace derives stable IDs for rules that are not stored in the database
+var ruleNamespace = uuid.MustParse("6f1c3c1e-8d0e-4b53-9a55-4a1f0f8e2b7a")
+
+type rulesFile struct {
+	Merchants           map[string]ruleTarget       `json:"merchants"`
+	DescriptionKeywords map[string]ruleTarget       `json:"description_keywords"`
+	Rules               []models.ClassificationRule `json:"rules"`
+}
+
+type ruleTarget struct {
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+}
+
+// LoadFile reads rules from a classification_rules.json file. The legacy
+// "merchants" and "description_keywords" maps become exact merchant and
+// substring rules; the "rules" array holds rules with explicit conditions.
+func LoadFile(path string) ([]models.ClassificationRule, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f rulesFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+
+	var rules []models.ClassificationRule
+	for _, name := range sortedKeys(f.Merchants) {
+		t := f.Merchants[name]
+		rules = append(rules, models.ClassificationRule{
+			ID:          stableID("merchant:" + name),
+			Name:        "merchant " + name,
+			Priority:    MerchantPriority,
+			Conditions:  models.RuleConditions{Merchant: name},
+			Category:    t.Category,
+			Subcategory: t.Subcategory,
+		})
+	}
+	for _, kw := range sortedKeys(f.DescriptionKeywords) {
+		t := f.DescriptionKeywords[kw]
+		rules = append(rules, models.ClassificationRule{
+			ID:          stableID("keyword:" + kw),
+			Name:        "keyword " + kw,
+			Priority:    KeywordPriority,
+			Conditions:  models.RuleConditions{Contains: []string{kw}},
+			Category:    t.Category,
+			Subcategory: t.Subcategory,
+		})
+	}
+	for _, r := range f.Rules {
+		if r.ID == uuid.Nil {
+			r.ID = stableID("rule:" + r.Name)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// builtinRules are the keyword rules that used to be hard-coded in common.InferCategory
+func builtinRules() []models.ClassificationRule {
+	table := []struct {
+		name     string
+		keywords []string
+		cat      string
+		sub      string
+	}{
+		{"impuestos", []string{"iva", "percepción", "ganancias", "tax", "impuesto", "sircreb", "arca", "afip"}, "impuestos", "impuestos y contribuciones"},
+		{"servicios digitales", []string{"netflix", "spotify", "youtube", "primevideo", "disney", "steam"}, "entretenimiento", "servicios digitales"},
+		{"delivery", []string{"pedidosya", "rappi", "mcdonalds", "burger", "grido", "mostaza"}, "comida", "delivery"},
+		{"servicios hogar", []string{"metrogas", "aysa", "edenor", "edesur", "personal flow", "claro", "telecom"}, "servicios", "hogar"},
+		{"comisiones/intereses", []string{"intereses pagados", "mantenimiento"}, "financiero", "comisiones/intereses"},
+		{"reintegros", []string{"reintegro promoción", "devolucion"}, "ingresos", "reintegros"},
+		{"sueldo", []string{"sueldo", "haberes"}, "ingresos", "sueldo"},
+	}
+
+	rules := make([]models.ClassificationRule, len(table))
+	for i, t := range table {
+		rules[i] = models.ClassificationRule{
+			ID:          stableID("builtin:" + t.name),
+			Name:        "builtin " + t.name,
+			Priority:    BuiltinPriority + i,
+			Conditions:  models.RuleConditions{Contains: t.keywords},
+			Category:    t.cat,
+			Subcategory: t.sub,
+		}
+	}
+	return rules
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultEngine *Engine
+)
+
+// Default returns the global engine: rules from the classification rules file
+// (CLASSIFICATION_RULES or ./classification_rules.json), global rules stored
+// in the database and the built-in keyword rules, in that order of priority.
+func Default() *Engine {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultEngine == nil {
+		defaultEngine = loadDefault()
+	}
+	return defaultEngine
+}
+
+// Reload discards the cached global engine so the next Default call re-reads its rules
+func Reload() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultEngine = nil
+}
+
+// ForUser returns the global engine extended with the user's own rules
+func ForUser(userID uuid.UUID) (*Engine, error) {
+	return Default().Extend(db.GetDB().GetClassificationRules(userID))
+}
+
+// Validate reports whether a rule can be compiled into an engine
+func Validate(rule models.ClassificationRule) error {
+	_, err := compile(rule)
+	return err
+}
+
+func loadDefault() *Engine {
+	path := os.Getenv("CLASSIFICATION_RULES")
+	if path == "" {
+		path = DefaultRulesPath
+	}
+
+	var rules []models.ClassificationRule
+	fileRules, err := LoadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("classifier: ignoring %s: %v", path, err)
+	}
+	rules = append(rules, fileRules...)
+	rules = append(rules, db.GetDB().GetClassificationRules(uuid.Nil)...)
+	rules = append(rules, builtinRules()...)
+
+	engine, err := New(rules)
+	if err != nil {
+		log.Printf("classifier: falling back to built-in rules: %v", err)
+		engine, _ = New(builtinRules())
+	}
+	return engine
+}
+
+func stableID(key string) uuid.UUID {
+	return uuid.NewSHA1(ruleNamespace, []byte(key))
+}
+
+func sortedKeys(m map[string]ruleTarget) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
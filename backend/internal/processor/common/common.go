@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
 )
 
 // Normalizer defines the interface for different bank report parsers
@@ -16,6 +17,12 @@ type Normalizer interface {
 	Normalize(filePath string) ([]models.Transaction, error)
 }
 
+// StatementReporter is implemented by parsers that also extract statement-level
+// data such as closing balances. Statements is valid after Normalize returns.
+type StatementReporter interface {
+	Statements() []models.Statement
+}
+
 // GenerateID creates a deterministic transaction_id
 func GenerateID(source, account, date, amount, rawDescription string) string {
 	payload := fmt.Sprintf("%s%s%s%s%s", source, account, date, amount, rawDescription)
@@ -23,10 +30,26 @@ func GenerateID(source, account, date, amount, rawDescription string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// CleanAmount parses a string into a float64, handling various formatting styles
-func CleanAmount(amountStr string) float64 {
+// DisambiguateIDs gives distinct IDs to identical rows of one statement, such
+// as two equal coffees on the same day, which GenerateID would collapse. The
+// first occurrence keeps its ID so stored transactions still match; the n-th
+// repeat gets a hash of that ID and n. Rows are numbered in statement order,
+// so re-importing the same statement yields the same IDs.
+func DisambiguateIDs(txs []models.Transaction) {
+	seen := make(map[string]int)
+	for i := range txs {
+		id := txs[i].ID
+		if n := seen[id]; n > 0 {
+			txs[i].ID = GenerateID(id, "#", strconv.Itoa(n), "", "")
+		}
+		seen[id]++
+	}
+}
+
+// CleanAmount parses a string into an exact amount, handling various formatting styles
+func CleanAmount(amountStr string) money.Amount {
 	if amountStr == "" || amountStr == "-" || strings.ToLower(amountStr) == "nan" {
-		return 0.0
+		return 0
 	}
 
 	// Remove currency symbols and other non-numeric chars except . , -
@@ -34,7 +57,7 @@ func CleanAmount(amountStr string) float64 {
 	clean := re.ReplaceAllString(amountStr, "")
 	clean = strings.TrimSpace(clean)
 	if clean == "" {
-		return 0.0
+		return 0
 	}
 
 	// Handle ARG/International formats: 1.234,56 or 1234,56
@@ -45,39 +68,28 @@ func CleanAmount(amountStr string) float64 {
 		clean = strings.ReplaceAll(clean, ",", ".")
 	}
 
-	val, err := strconv.ParseFloat(clean, 64)
+	val, err := money.Parse(clean)
 	if err != nil {
-		return 0.0
+		return 0
 	}
 	return val
 }
 
-// InferCategory determines category and subcategory based on description
-func InferCategory(description string) (category *string, subcategory *string) {
-	desc := strings.ToLower(description)
-
-	rules := []struct {
-		keywords []string
-		cat      string
-		sub      string
-	}{
-		{[]string{"iva", "percepción", "ganancias", "tax", "impuesto", "sircreb", "arca", "afip"}, "impuestos", "impuestos y contribuciones"},
-		{[]string{"netflix", "spotify", "youtube", "primevideo", "disney", "steam"}, "entretenimiento", "servicios digitales"},
-		{[]string{"pedidosya", "rappi", "mcdonalds", "burger", "grido", "mostaza"}, "comida", "delivery"},
-		{[]string{"metrogas", "aysa", "edenor", "edesur", "personal flow", "claro", "telecom"}, "servicios", "hogar"},
-		{[]string{"intereses pagados", "mantenimiento"}, "financiero", "comisiones/intereses"},
-		{[]string{"reintegro promoción", "devolucion"}, "ingresos", "reintegros"},
-		{[]string{"sueldo", "haberes"}, "ingresos", "sueldo"},
-	}
+// Sample holds the sniffed content of a statement file used for format detection
+type Sample struct {
+	Path string
+	Kind string     // pdf, xlsx or text
+	Text string     // PDF plain text or the leading bytes of a text file
+	Rows [][]string // leading rows of the first XLSX sheet
+}
 
-	for _, rule := range rules {
-		for _, kw := range rule.keywords {
-			if strings.Contains(desc, kw) {
-				c, s := rule.cat, rule.sub
-				return &c, &s
-			}
-		}
-	}
+// Detection reports how confident a parser is that it understands a Sample
+type Detection struct {
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
 
-	return nil, nil
+// Detector is implemented by parsers that can recognise their format from file content
+type Detector interface {
+	Detect(sample *Sample) Detection
 }
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+func TestDisambiguateIDs(t *testing.T) {
+	statement := func() []models.Transaction {
+		coffee := GenerateID("brubank", "caja_ahorro_pesos", "2024-05-02", "-3500.00", "CAFE MARTINEZ")
+		lunch := GenerateID("brubank", "caja_ahorro_pesos", "2024-05-02", "-12000.00", "ALMUERZO")
+		return []models.Transaction{{ID: coffee}, {ID: lunch}, {ID: coffee}, {ID: coffee}}
+	}
+
+	txs := statement()
+	plain := txs[0].ID
+	DisambiguateIDs(txs)
+	if txs[0].ID != plain {
+		t.Errorf("first coffee ID = %s, want the plain GenerateID %s", txs[0].ID, plain)
+	}
+	seen := make(map[string]bool)
+	for _, tx := range txs {
+		if seen[tx.ID] {
+			t.Fatalf("IDs are not distinct: %v", txs)
+		}
+		seen[tx.ID] = true
+	}
+
+	// Importing the same statement again yields the same IDs
+	again := statement()
+	DisambiguateIDs(again)
+	for i := range txs {
+		if again[i].ID != txs[i].ID {
+			t.Errorf("row %d ID = %s on the second import, want %s", i, again[i].ID, txs[i].ID)
+		}
+	}
+}
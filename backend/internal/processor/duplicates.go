@@ -0,0 +1,196 @@
+package processor
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+const (
+	// DuplicateMergeScore is the description similarity at which a row from an
+	// overlapping statement is merged into the stored transaction automatically
+	DuplicateMergeScore = 0.9
+	// DuplicateReviewScore is the lowest similarity sent to the review queue
+	DuplicateReviewScore = 0.6
+)
+
+// FindDuplicates looks for rows of batch that are the same movement as a stored
+// transaction in history, or as an earlier row of batch from another file: same
+// account, date, currency and amount, from another upload, with similar
+// descriptions. Rows of one upload are never duplicates of each other. Rows that
+// are merged get DuplicateOf set. known holds the user's recorded candidates:
+// merged pairs stay merged and reviewed pairs are not proposed again. It
+// returns the new candidates.
+func FindDuplicates(batch, history []models.Transaction, known []models.DuplicateCandidate) ([]models.Transaction, []models.DuplicateCandidate) {
+	recorded := make(map[[2]string]models.DuplicateCandidate, len(known))
+	claimed := make(map[string]bool)
+	for _, c := range known {
+		recorded[[2]string{c.TransactionID, c.DuplicateID}] = c
+		if c.Status != models.DuplicateDismissed {
+			claimed[c.TransactionID], claimed[c.DuplicateID] = true, true
+		}
+	}
+
+	type key struct{ account, date, currency string }
+	keyOf := func(tx models.Transaction) key {
+		return key{accountOf(tx), dayOf(tx.Date), strings.ToUpper(tx.Currency)}
+	}
+	stored := make(map[key][]int)
+	for i, tx := range history {
+		if tx.DuplicateOf == nil {
+			stored[keyOf(tx)] = append(stored[keyOf(tx)], i)
+		}
+	}
+
+	// pair proposes hiding a batch row as a duplicate of kept, a stored
+	// transaction or, when keptRow is not -1, an earlier row of the batch
+	type pair struct {
+		row     int
+		kept    models.Transaction
+		keptRow int
+		score   float64
+	}
+	var pairs []pair
+	compare := func(i int, kept models.Transaction, keptRow int) {
+		tx := batch[i]
+		if tx.Amount != kept.Amount || tx.UploadID == kept.UploadID {
+			return
+		}
+		if c, ok := recorded[[2]string{kept.ID, tx.ID}]; ok {
+			if c.Status == models.DuplicateMerged {
+				batch[i].DuplicateOf = &c.TransactionID
+			}
+			return
+		}
+		if score := DescriptionSimilarity(tx.Description, kept.Description); score >= DuplicateReviewScore {
+			pairs = append(pairs, pair{row: i, kept: kept, keptRow: keptRow, score: score})
+		}
+	}
+	earlier := make(map[key][]int)
+	for i := range batch {
+		k := keyOf(batch[i])
+		for _, j := range stored[k] {
+			compare(i, history[j], -1)
+		}
+		for _, j := range earlier[k] {
+			compare(i, batch[j], j)
+		}
+		earlier[k] = append(earlier[k], i)
+	}
+
+	// Each row pairs with at most one other transaction, best scores first
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].score > pairs[b].score })
+	var found []models.DuplicateCandidate
+	for _, p := range pairs {
+		tx, old := &batch[p.row], p.kept
+		if claimed[tx.ID] || claimed[old.ID] || (p.keptRow >= 0 && batch[p.keptRow].DuplicateOf != nil) {
+			continue
+		}
+		claimed[tx.ID], claimed[old.ID] = true, true
+
+		c := models.DuplicateCandidate{
+			ID:            uuid.New(),
+			UserID:        tx.UserID,
+			TransactionID: old.ID,
+			DuplicateID:   tx.ID,
+			Score:         p.score,
+			Status:        models.DuplicatePending,
+			CreatedAt:     time.Now(),
+		}
+		if p.score >= DuplicateMergeScore {
+			c.Status = models.DuplicateMerged
+			c.ResolvedAt = &c.CreatedAt
+			tx.DuplicateOf = &c.TransactionID
+		}
+		found = append(found, c)
+	}
+	return batch, found
+}
+
+// ResolveDuplicate merges (hides the duplicate row) or dismisses (keeps both
+// rows) a duplicate candidate. Dismissing a merged candidate restores the row.
+func ResolveDuplicate(userID, id uuid.UUID, status string) (models.DuplicateCandidate, error) {
+	c, err := db.GetDB().GetDuplicateCandidate(userID, id)
+	if err != nil {
+		return c, err
+	}
+	dup, err := db.GetDB().GetTransaction(userID, c.DuplicateID)
+	if err != nil {
+		return c, err
+	}
+
+	dup.DuplicateOf = nil
+	if status == models.DuplicateMerged {
+		dup.DuplicateOf = &c.TransactionID
+	}
+	if err := db.GetDB().UpdateTransaction(dup); err != nil {
+		return c, err
+	}
+
+	now := time.Now()
+	c.Status, c.ResolvedAt = status, &now
+	return c, db.GetDB().UpdateDuplicateCandidate(c)
+}
+
+// DescriptionSimilarity scores how alike two descriptions of the same movement
+// are, from 0 to 1. Case, punctuation and spacing are ignored, and a description
+// truncated by one of the exports still scores high.
+func DescriptionSimilarity(a, b string) float64 {
+	a, b = compactDescription(a), compactDescription(b)
+	if a == b {
+		return 1
+	}
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	if len(short) >= 6 && strings.HasPrefix(long, short) {
+		return 0.95
+	}
+	longest := len([]rune(long))
+	if longest == 0 {
+		return 0
+	}
+	ratio := 1 - float64(levenshtein([]rune(a), []rune(b)))/float64(longest)
+	return math.Round(ratio*100) / 100
+}
+
+// compactDescription lowercases s and keeps words of letters and digits
+// separated by single spaces
+func compactDescription(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+func TestFindDuplicatesWithinBatch(t *testing.T) {
+	pdf, xlsx := uuid.New(), uuid.New()
+	row := func(id string, upload uuid.UUID, description string) models.Transaction {
+		return models.Transaction{
+			ID: id, UploadID: upload, Source: "santander", Account: "caja_ahorro_pesos",
+			Date: "2024-04-10", Amount: money.Amount(-875000), Currency: "ARS", Description: description,
+		}
+	}
+	batch := []models.Transaction{
+		row("pdf-1", pdf, "COMPRA SUPERMERCADO COTO"),
+		// The same purchase twice on one statement is two movements
+		row("pdf-2", pdf, "COMPRA SUPERMERCADO COTO"),
+		row("xlsx-1", xlsx, "Compra Supermercado Coto"),
+		row("xlsx-2", xlsx, "Compra supermercado coto"),
+	}
+
+	got, found := FindDuplicates(batch, nil, nil)
+	if len(found) != 2 {
+		t.Fatalf("found %d candidates, want 2: %+v", len(found), found)
+	}
+	hidden := make(map[string]string)
+	for _, tx := range got {
+		if tx.DuplicateOf != nil {
+			hidden[tx.ID] = *tx.DuplicateOf
+		}
+	}
+	if len(hidden) != 2 || hidden["xlsx-1"] == "" || hidden["xlsx-2"] == "" || hidden["xlsx-1"] == hidden["xlsx-2"] {
+		t.Errorf("hidden rows = %v, want each xlsx row merged into its own pdf row", hidden)
+	}
+	for _, c := range found {
+		if c.Status != models.DuplicateMerged || c.Score != 1 {
+			t.Errorf("candidate %s/%s = %s %v, want merged with score 1", c.TransactionID, c.DuplicateID, c.Status, c.Score)
+		}
+	}
+}
+
+func TestFindDuplicatesSameUpload(t *testing.T) {
+	upload := uuid.New()
+	batch := []models.Transaction{
+		{ID: "a", UploadID: upload, Account: "cuenta", Date: "2024-04-10", Amount: -500, Currency: "ARS", Description: "CAFE"},
+		{ID: "b", UploadID: upload, Account: "cuenta", Date: "2024-04-10", Amount: -500, Currency: "ARS", Description: "CAFE"},
+	}
+	got, found := FindDuplicates(batch, nil, nil)
+	if len(found) != 0 || got[0].DuplicateOf != nil || got[1].DuplicateOf != nil {
+		t.Errorf("rows of one upload were paired: %+v", found)
+	}
+}
@@ -2,21 +2,26 @@ package processor
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/fx"
 	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
 	"github.com/juank/finance-ai/backend/internal/processor/common"
-	"github.com/juank/finance-ai/backend/internal/processor/parsers"
+	"github.com/juank/finance-ai/backend/internal/processor/merchants"
 )
 
 type Engine struct {
 	OutputDir string
 	UserID    uuid.UUID
+	AccountID uuid.UUID // account to import into; resolved from each row when nil
 }
 
 func NewEngine(outputDir string, userID uuid.UUID) *Engine {
@@ -31,19 +36,21 @@ func (e *Engine) RunAll() error {
 	// For production, we prefer ProcessFile called from API
 	var allFilesTransactions []models.Transaction
 
-	// Simplified for brevity in this example, normally you'd map dirs to parsers
 	dirs := []struct {
-		path   string
-		ext    string
-		parser common.Normalizer
+		path     string
+		parserID string
 	}{
-		{"/Users/juank/Documents/Cuentas/Bancos/Brubank", ".pdf", &parsers.BrubankPDFParser{}},
-		{"/Users/juank/Documents/Cuentas/Bancos/MercadoPago", ".csv", &parsers.MercadoPagoParser{}},
-		{"/Users/juank/Documents/Cuentas/Bancos/Deel", ".csv", &parsers.DeelParser{}},
+		{"/Users/juank/Documents/Cuentas/Bancos/Brubank", "brubank_pdf"},
+		{"/Users/juank/Documents/Cuentas/Bancos/MercadoPago", "mercadopago_csv"},
+		{"/Users/juank/Documents/Cuentas/Bancos/Deel", "deel_csv"},
 	}
 
 	for _, d := range dirs {
-		txs, _ := e.processDir(d.path, d.ext, d.parser, uuid.Nil)
+		info, ok := LookupParser(d.parserID)
+		if !ok {
+			return fmt.Errorf("parser %q is not registered", d.parserID)
+		}
+		txs, _ := e.processDir(d.path, info)
 		allFilesTransactions = append(allFilesTransactions, txs...)
 	}
 
@@ -55,6 +62,30 @@ func (e *Engine) ProcessFile(filePath string, parser common.Normalizer, uploadID
 	if err != nil {
 		return nil, err
 	}
+	common.DisambiguateIDs(txs)
+	if e.UserID != uuid.Nil {
+		if _, err := ResolveAccounts(e.UserID, e.AccountID, txs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve merchants through the user's alias table, apply the user's own
+	// classification rules on top of the global ones, and fall back to the
+	// model learned from the user's labelled transactions
+	if e.UserID != uuid.Nil {
+		rules, err := classifier.ForUser(e.UserID)
+		if err != nil {
+			return nil, err
+		}
+		aliases := merchants.NewAliases(db.GetDB().GetMerchantAliases(e.UserID))
+		model := classifier.Train(db.GetDB().GetTransactions(e.UserID))
+		for i := range txs {
+			aliases.Apply(&txs[i])
+			if !rules.Classify(&txs[i]) {
+				model.Fallback(&txs[i])
+			}
+		}
+	}
 
 	// Enrich with metadata
 	for i := range txs {
@@ -69,23 +100,99 @@ func (e *Engine) ProcessFile(filePath string, parser common.Normalizer, uploadID
 func (e *Engine) SaveAndConsolidate(txs []models.Transaction) error {
 	// 5. Neutralization & Deduplication
 	txs = deduplicate(txs)
-	txs = NeutralizeTransfers(txs)
+	opts := MatchOptionsFor(e.UserID)
+	var links []models.TransferLink
+	var history []models.Transaction
+	var known []models.DuplicateCandidate
+	if e.UserID != uuid.Nil {
+		links = db.GetDB().GetTransferLinks(e.UserID)
+		history = e.storedWindow(txs, opts)
+		known = db.GetDB().GetDuplicateCandidates(e.UserID)
+	}
+	txs, duplicates := FindDuplicates(txs, history, known)
+
+	// Match across the new rows and the stored ones they could pair with, and
+	// persist the stored rows whose flags changed along with the new ones
+	batch := len(txs)
+	all, found := NeutralizeTransfers(append(txs, history...), links, opts, fx.Default())
+	txs = all[:batch:batch]
+	for i, tx := range all[batch:] {
+		if tx.Neutralized != history[i].Neutralized {
+			txs = append(txs, tx)
+		}
+	}
 
 	// Persist to DB
 	if err := db.GetDB().UpsertTransactions(txs); err != nil {
 		return err
 	}
+	if e.UserID != uuid.Nil {
+		for i := range found {
+			found[i].ID = uuid.New()
+			found[i].UserID = e.UserID
+			found[i].CreatedAt = time.Now()
+			if err := db.GetDB().CreateTransferLink(found[i]); err != nil {
+				return err
+			}
+		}
+		for _, c := range duplicates {
+			if err := db.GetDB().CreateDuplicateCandidate(c); err != nil {
+				return err
+			}
+		}
+	}
 
 	// 6. Sort and save consolidated JSON (Optional/Legacy support)
 	sort.Slice(txs, func(i, j int) bool {
 		return txs[i].Date > txs[j].Date
 	})
 	e.saveJSON("consolidated_transactions.json", txs)
+	e.saveJSON("transfer_links.json", found)
 
 	return nil
 }
 
-func (e *Engine) processDir(dir, ext string, parser common.Normalizer, uploadID uuid.UUID) ([]models.Transaction, error) {
+// storedWindow loads the user's stored transactions dated close enough to txs
+// to be the other side of one of their transfers, leaving out those in txs
+func (e *Engine) storedWindow(txs []models.Transaction, opts MatchOptions) []models.Transaction {
+	var first, last time.Time
+	inBatch := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		inBatch[tx.ID] = true
+		date, err := time.Parse("2006-01-02", dayOf(tx.Date))
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if date.After(last) {
+			last = date
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	margin := opts.DaysBefore
+	if opts.DaysAfter > margin {
+		margin = opts.DaysAfter
+	}
+	from := first.AddDate(0, 0, -margin).Format("2006-01-02")
+	to := last.AddDate(0, 0, margin).Format("2006-01-02")
+
+	var history []models.Transaction
+	for _, tx := range db.GetDB().GetTransactionsBetween(e.UserID, from, to) {
+		if !inBatch[tx.ID] {
+			history = append(history, tx)
+		}
+	}
+	return history
+}
+
+// processDir imports every file of dir as its own upload, so rows of
+// overlapping statements in the directory are compared as duplicates
+func (e *Engine) processDir(dir string, info ParserInfo) ([]models.Transaction, error) {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		return nil, nil
 	}
@@ -97,8 +204,12 @@ func (e *Engine) processDir(dir, ext string, parser common.Normalizer, uploadID
 
 	var all []models.Transaction
 	for _, f := range files {
-		if !f.IsDir() && filepath.Ext(f.Name()) == ext {
-			txs, err := e.ProcessFile(filepath.Join(dir, f.Name()), parser, uploadID)
+		if !f.IsDir() && hasExtension(f.Name(), info.Extensions) {
+			upload := models.Upload{ID: uuid.New(), UserID: e.UserID, Filename: f.Name(), Status: "processing", CreatedAt: time.Now()}
+			if e.UserID != uuid.Nil {
+				db.GetDB().CreateUpload(upload)
+			}
+			txs, err := e.ProcessFile(filepath.Join(dir, f.Name()), info.New(), upload.ID)
 			if err == nil {
 				all = append(all, txs...)
 			}
@@ -107,6 +218,16 @@ func (e *Engine) processDir(dir, ext string, parser common.Normalizer, uploadID
 	return all, nil
 }
 
+func hasExtension(name string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Engine) saveJSON(filename string, data interface{}) {
 	path := filepath.Join(e.OutputDir, filename)
 	file, _ := os.Create(path)
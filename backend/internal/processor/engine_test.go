@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// A transfer whose debit was imported with an earlier upload is linked when
+// the credit arrives, and both sides are stored neutralized
+func TestSaveAndConsolidateLinksStoredTransfer(t *testing.T) {
+	db.Instance = db.GetMemoryDB()
+	userID := uuid.New()
+	debit := models.Transaction{
+		ID: "mp-debit", UserID: userID, UploadID: uuid.New(), Source: "mercadopago", Account: "cuenta",
+		Date: "2024-06-03", Amount: money.Amount(-5000000), Currency: "ARS", Description: "Transferencia enviada Juan Perez",
+		Direction: "debit", IsTransfer: true,
+	}
+	if err := db.GetDB().UpsertTransactions([]models.Transaction{debit}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngine(t.TempDir(), userID)
+	credit := models.Transaction{
+		ID: "brubank-credit", UserID: userID, UploadID: uuid.New(), Source: "brubank", Account: "caja_ahorro_pesos",
+		Date: "2024-06-04", Amount: money.Amount(5000000), Currency: "ARS", Description: "TRANSFERENCIA RECIBIDA JUAN PEREZ",
+		Direction: "credit", IsTransfer: true,
+	}
+	if err := e.SaveAndConsolidate([]models.Transaction{credit}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{debit.ID, credit.ID} {
+		tx, err := db.GetDB().GetTransaction(userID, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tx.Neutralized {
+			t.Errorf("%s not neutralized", id)
+		}
+	}
+	links := db.GetDB().GetTransferLinks(userID)
+	if len(links) != 1 || links[0].DebitID != debit.ID || links[0].CreditID != credit.ID || links[0].Method != models.LinkAuto {
+		t.Fatalf("links = %+v, want one automatic link from %s to %s", links, debit.ID, credit.ID)
+	}
+	if links[0].DebitAccount != "mercadopago/cuenta" || links[0].CreditAccount != "brubank/caja_ahorro_pesos" {
+		t.Errorf("link accounts = %q, %q", links[0].DebitAccount, links[0].CreditAccount)
+	}
+}
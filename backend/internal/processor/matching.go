@@ -0,0 +1,247 @@
+package processor
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juank/finance-ai/backend/internal/fx"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// maxExactComponent bounds the size of a group of competing debits and credits
+// solved exactly; larger groups are matched greedily by score
+const maxExactComponent = 400
+
+// candidate is a debit/credit pair within the match tolerances
+type candidate struct {
+	debit, credit int // indexes into the transactions being neutralized
+	link          models.TransferLink
+}
+
+// creditIndex buckets credits by day and currency, sorted by amount, so the
+// credits near a debit are found with a few binary searches
+type creditIndex map[string]map[string][]indexedCredit
+
+type indexedCredit struct {
+	amount money.Amount
+	idx    int
+}
+
+func newCreditIndex(txs []models.Transaction, credits []int) creditIndex {
+	index := make(creditIndex)
+	for _, i := range credits {
+		day := dayOf(txs[i].Date)
+		currency := strings.ToUpper(txs[i].Currency)
+		if index[day] == nil {
+			index[day] = make(map[string][]indexedCredit)
+		}
+		index[day][currency] = append(index[day][currency], indexedCredit{amount: txs[i].Amount.Abs(), idx: i})
+	}
+	for _, byCurrency := range index {
+		for _, list := range byCurrency {
+			sort.Slice(list, func(i, j int) bool { return list[i].amount < list[j].amount })
+		}
+	}
+	return index
+}
+
+// near returns the credits whose date falls in the match window of debit and
+// whose amount is within the tolerance of the debit, converted when needed
+func (index creditIndex) near(debit models.Transaction, opts MatchOptions, rates *fx.Table) []int {
+	date, err := time.Parse("2006-01-02", dayOf(debit.Date))
+	if err != nil {
+		return nil
+	}
+	m := &matcher{opts: opts, rates: rates}
+
+	var found []int
+	for offset := -opts.DaysBefore; offset <= opts.DaysAfter; offset++ {
+		for currency, list := range index[date.AddDate(0, 0, offset).Format("2006-01-02")] {
+			target, tolerance, _, ok := m.target(debit, currency)
+			if !ok {
+				continue
+			}
+			// Bounds widened by a cent; match applies the exact tolerance
+			spread := target.Mul(tolerance)
+			lo, hi := target-spread-1, target+spread+1
+			start := sort.Search(len(list), func(i int) bool { return list[i].amount >= lo })
+			for _, c := range list[start:] {
+				if c.amount > hi {
+					break
+				}
+				found = append(found, c.idx)
+			}
+		}
+	}
+	sort.Ints(found)
+	return found
+}
+
+// bestMatching picks pairs so that no transaction is used twice, matching as
+// many pairs as possible with the highest total score. Candidates are split
+// into groups that compete for the same transactions, and each group is solved
+// on its own.
+func bestMatching(candidates []candidate) []candidate {
+	// Union-find over debits and credits
+	parent := make(map[int]int)
+	var find func(int) int
+	find = func(x int) int {
+		if p, ok := parent[x]; ok && p != x {
+			root := find(p)
+			parent[x] = root
+			return root
+		}
+		parent[x] = x
+		return x
+	}
+	// Credits are offset to keep them apart from debits with the same index
+	node := func(c candidate) (int, int) { return c.debit, -c.credit - 1 }
+	for _, c := range candidates {
+		d, cr := node(c)
+		parent[find(d)] = find(cr)
+	}
+
+	groups := make(map[int][]candidate)
+	var roots []int
+	for _, c := range candidates {
+		d, _ := node(c)
+		root := find(d)
+		if groups[root] == nil {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], c)
+	}
+
+	var chosen []candidate
+	for _, root := range roots {
+		chosen = append(chosen, solveGroup(groups[root])...)
+	}
+	return chosen
+}
+
+// solveGroup finds the best matching of one group of competing candidates
+func solveGroup(group []candidate) []candidate {
+	if len(group) == 1 {
+		return group
+	}
+
+	rowOf := make(map[int]int)
+	colOf := make(map[int]int)
+	for _, c := range group {
+		if _, ok := rowOf[c.debit]; !ok {
+			rowOf[c.debit] = len(rowOf)
+		}
+		if _, ok := colOf[c.credit]; !ok {
+			colOf[c.credit] = len(colOf)
+		}
+	}
+	size := len(rowOf)
+	if len(colOf) > size {
+		size = len(colOf)
+	}
+	if len(rowOf)+len(colOf) > maxExactComponent {
+		return greedyMatching(group)
+	}
+
+	// Each pair is worth more than the scores of all pairs together, so the
+	// number of pairs is maximized first and the total score second
+	bonus := float64(size) + 1
+	cost := make([][]float64, size)
+	edge := make([][]int, size)
+	for i := range cost {
+		cost[i] = make([]float64, size)
+		edge[i] = make([]int, size)
+		for j := range edge[i] {
+			edge[i][j] = -1
+		}
+	}
+	for k, c := range group {
+		i, j := rowOf[c.debit], colOf[c.credit]
+		cost[i][j] = -(bonus + c.link.Score)
+		edge[i][j] = k
+	}
+
+	var chosen []candidate
+	for i, j := range hungarian(cost) {
+		if k := edge[i][j]; k >= 0 {
+			chosen = append(chosen, group[k])
+		}
+	}
+	return chosen
+}
+
+// greedyMatching takes pairs by descending score
+func greedyMatching(group []candidate) []candidate {
+	sorted := append([]candidate(nil), group...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].link.Score > sorted[j].link.Score })
+	used := make(map[int]bool)
+	var chosen []candidate
+	for _, c := range sorted {
+		if used[c.debit] || used[-c.credit-1] {
+			continue
+		}
+		used[c.debit], used[-c.credit-1] = true, true
+		chosen = append(chosen, c)
+	}
+	return chosen
+}
+
+// hungarian solves the square assignment problem for cost, returning the
+// column assigned to each row with the minimum total cost
+func hungarian(cost [][]float64) []int {
+	n := len(cost)
+	// 1-based potentials and matching as in the classic O(n³) formulation
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1) // p[j]: row matched to column j
+	way := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		for p[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.Inf(1), 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := cost[i0-1][j-1] - u[i0] - v[j]; cur < minv[j] {
+					minv[j], way[j] = cur, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := make([]int, n)
+	for j := 1; j <= n; j++ {
+		if p[j] > 0 {
+			assignment[p[j]-1] = j - 1
+		}
+	}
+	return assignment
+}
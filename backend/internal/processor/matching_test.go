@@ -0,0 +1,217 @@
+package processor
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+func pair(debit, credit int, score float64) candidate {
+	return candidate{debit: debit, credit: credit, link: models.TransferLink{Score: score}}
+}
+
+// pairsOf lists chosen pairs as [debit, credit], sorted
+func pairsOf(chosen []candidate) [][2]int {
+	pairs := make([][2]int, len(chosen))
+	for i, c := range chosen {
+		pairs[i] = [2]int{c.debit, c.credit}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+func assertPairs(t *testing.T, chosen []candidate, want [][2]int) {
+	t.Helper()
+	got := pairsOf(chosen)
+	if len(got) != len(want) {
+		t.Fatalf("pairs = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("pairs = %v, want %v", got, want)
+		}
+	}
+}
+
+// assertDisjoint fails when a debit or credit is used by two chosen pairs
+func assertDisjoint(t *testing.T, chosen []candidate) {
+	t.Helper()
+	debits, credits := make(map[int]bool), make(map[int]bool)
+	for _, c := range chosen {
+		if debits[c.debit] || credits[c.credit] {
+			t.Fatalf("pair %d/%d reuses a transaction", c.debit, c.credit)
+		}
+		debits[c.debit], credits[c.credit] = true, true
+	}
+}
+
+func TestHungarian(t *testing.T) {
+	cost := [][]float64{
+		{4, 1, 3},
+		{2, 0, 5},
+		{3, 2, 2},
+	}
+	// Rows 0, 1, 2 take columns 1, 0, 2 for 1 + 2 + 2 = 5
+	got := hungarian(cost)
+	want := []int{1, 0, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("hungarian = %v, want %v", got, want)
+		}
+	}
+
+	// Random matrices against every permutation
+	rng := rand.New(rand.NewSource(1))
+	for n := 1; n <= 6; n++ {
+		for round := 0; round < 20; round++ {
+			cost := make([][]float64, n)
+			for i := range cost {
+				cost[i] = make([]float64, n)
+				for j := range cost[i] {
+					cost[i][j] = -math.Round(rng.Float64()*100) / 10
+				}
+			}
+			assignment := hungarian(cost)
+			seen := make(map[int]bool)
+			total := 0.0
+			for i, j := range assignment {
+				if seen[j] {
+					t.Fatalf("hungarian(%v) = %v assigns column %d twice", cost, assignment, j)
+				}
+				seen[j] = true
+				total += cost[i][j]
+			}
+			if best := bruteForce(cost); math.Abs(total-best) > 1e-9 {
+				t.Fatalf("hungarian(%v) = %v costs %v, want %v", cost, assignment, total, best)
+			}
+		}
+	}
+}
+
+// bruteForce returns the minimum cost of an assignment by trying every permutation
+func bruteForce(cost [][]float64) float64 {
+	n := len(cost)
+	cols := make([]int, n)
+	for i := range cols {
+		cols[i] = i
+	}
+	best := math.Inf(1)
+	var permute func(k int)
+	permute = func(k int) {
+		if k == n {
+			total := 0.0
+			for i, j := range cols {
+				total += cost[i][j]
+			}
+			best = math.Min(best, total)
+			return
+		}
+		for i := k; i < n; i++ {
+			cols[k], cols[i] = cols[i], cols[k]
+			permute(k + 1)
+			cols[k], cols[i] = cols[i], cols[k]
+		}
+	}
+	permute(0)
+	return best
+}
+
+func TestBestMatching(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []candidate
+		want       [][2]int
+	}{
+		{
+			name:       "single pair",
+			candidates: []candidate{pair(0, 0, 0.7)},
+			want:       [][2]int{{0, 0}},
+		},
+		{
+			// Taking the best pair first would leave debit 1 unmatched
+			name:       "more pairs beat a better pair",
+			candidates: []candidate{pair(0, 0, 0.9), pair(0, 1, 0.95), pair(1, 1, 0.5)},
+			want:       [][2]int{{0, 0}, {1, 1}},
+		},
+		{
+			name:       "highest total score",
+			candidates: []candidate{pair(0, 0, 0.9), pair(0, 1, 0.8), pair(1, 0, 0.85), pair(1, 1, 0.3)},
+			want:       [][2]int{{0, 1}, {1, 0}},
+		},
+		{
+			name:       "more debits than credits",
+			candidates: []candidate{pair(0, 0, 0.6), pair(1, 0, 0.8), pair(2, 0, 0.7)},
+			want:       [][2]int{{1, 0}},
+		},
+		{
+			name: "independent groups",
+			candidates: []candidate{
+				pair(0, 0, 0.9), pair(0, 1, 0.95), pair(1, 1, 0.5),
+				pair(5, 7, 0.4),
+				pair(3, 3, 0.6), pair(4, 3, 0.9),
+			},
+			want: [][2]int{{0, 0}, {1, 1}, {4, 3}, {5, 7}},
+		},
+		{
+			name: "none",
+			want: [][2]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen := bestMatching(tt.candidates)
+			assertDisjoint(t, chosen)
+			assertPairs(t, chosen, tt.want)
+		})
+	}
+}
+
+// chain links debit i to credit i (0.9) and to credit i+1 (0.95), so taking
+// the best pairs first matches one pair fewer than the exact solution
+func chain(n int) []candidate {
+	var candidates []candidate
+	for i := 0; i < n; i++ {
+		candidates = append(candidates, pair(i, i, 0.9))
+		if i+1 < n {
+			candidates = append(candidates, pair(i, i+1, 0.95))
+		}
+	}
+	return candidates
+}
+
+func TestBestMatchingGreedyAboveLimit(t *testing.T) {
+	// 200 debits and 200 credits are solved exactly: every debit i takes credit i
+	n := maxExactComponent / 2
+	chosen := bestMatching(chain(n))
+	assertDisjoint(t, chosen)
+	if len(chosen) != n {
+		t.Errorf("exact matching of %d nodes chose %d pairs, want %d", 2*n, len(chosen), n)
+	}
+
+	// One more of each exceeds the limit and is matched greedily by score
+	n++
+	chosen = bestMatching(chain(n))
+	assertDisjoint(t, chosen)
+	if len(chosen) != n-1 {
+		t.Errorf("greedy matching of %d nodes chose %d pairs, want %d", 2*n, len(chosen), n-1)
+	}
+	for _, c := range chosen {
+		if c.credit != c.debit+1 {
+			t.Errorf("greedy matching chose %d/%d, want only the 0.95 pairs", c.debit, c.credit)
+		}
+	}
+}
+
+func TestGreedyMatching(t *testing.T) {
+	chosen := greedyMatching([]candidate{pair(0, 0, 0.9), pair(0, 1, 0.95), pair(1, 1, 0.5), pair(1, 0, 0.2)})
+	assertDisjoint(t, chosen)
+	assertPairs(t, chosen, [][2]int{{0, 1}, {1, 0}})
+}
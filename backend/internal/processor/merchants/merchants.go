@@ -0,0 +1,103 @@
+package merchants
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+// processorPrefix matches payment processor prefixes such as PAYU*AR*, DLO* or MERPAGO*
+var processorPrefix = regexp.MustCompile(`(?i)^(payu|dlo|dlocal|merpago|mercadopago|mp|paypal|sq|tst|ebanx|stripe)\s*\*\s*([a-z]{2}\s*\*\s*)?`)
+
+// Normalize strips payment processor prefixes and extra whitespace from a raw
+// merchant, e.g. "Payu*ar*uber" and "PAYU*AR*UBER" both become "Uber".
+func Normalize(raw string) string {
+	name := strings.Join(strings.Fields(raw), " ")
+	for {
+		stripped := processorPrefix.ReplaceAllString(name, "")
+		if stripped == name || strings.TrimSpace(stripped) == "" {
+			break
+		}
+		name = strings.TrimSpace(stripped)
+	}
+	name = strings.Trim(name, " *-.,")
+
+	// Title-case names written in a single case so "UBER" and "uber" both
+	// become "Uber"; short upper-case words such as "YPF" are kept as acronyms
+	if name == strings.ToLower(name) || name == strings.ToUpper(name) {
+		words := strings.Fields(name)
+		for i, w := range words {
+			if w == strings.ToUpper(w) && len([]rune(w)) <= 3 {
+				continue
+			}
+			r := []rune(strings.ToLower(w))
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+		name = strings.Join(words, " ")
+	}
+	return name
+}
+
+// Key is the comparison form of a merchant: normalized, lower case, letters and digits only
+func Key(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(Normalize(raw)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Aliases maps merchant keys to the canonical name chosen by the user
+type Aliases map[string]string
+
+// NewAliases indexes a user's alias table
+func NewAliases(aliases []models.MerchantAlias) Aliases {
+	a := make(Aliases, len(aliases))
+	for _, alias := range aliases {
+		a[Key(alias.Alias)] = alias.Canonical
+	}
+	return a
+}
+
+// Canonical returns the canonical name for a raw merchant
+func (a Aliases) Canonical(raw string) string {
+	if canonical, ok := a[Key(raw)]; ok {
+		return canonical
+	}
+	return Normalize(raw)
+}
+
+// Apply keeps the parser's merchant as RawMerchant and sets Merchant to its
+// canonical name. Merchants edited by the user are left alone. It reports
+// whether Merchant changed.
+func (a Aliases) Apply(tx *models.Transaction) bool {
+	if tx.MerchantLocked {
+		return false
+	}
+	raw := tx.RawMerchant
+	if raw == nil {
+		raw = tx.Merchant
+	}
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return false
+	}
+
+	rawName := strings.TrimSpace(*raw)
+	tx.RawMerchant = &rawName
+	canonical := a.Canonical(rawName)
+	if tx.Merchant != nil && *tx.Merchant == canonical {
+		return false
+	}
+	tx.Merchant = &canonical
+	return true
+}
+
+// Apply normalizes the merchant of tx without any user aliases
+func Apply(tx *models.Transaction) bool {
+	return Aliases(nil).Apply(tx)
+}
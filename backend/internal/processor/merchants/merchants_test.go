@@ -0,0 +1,96 @@
+package merchants
+
+import (
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"Payu*ar*uber", "Uber"},
+		{"PAYU*AR*UBER", "Uber"},
+		{"Dlo*didi", "Didi"},
+		{"DLOCAL * SPOTIFY", "Spotify"},
+		{"Merpago*meli", "Meli"},
+		{"MERPAGO*MERCADOLIBRE", "Mercadolibre"},
+		// Stacked processor prefixes
+		{"MP*PAYU*AR*NETFLIX", "Netflix"},
+		// Short upper-case words are kept as acronyms
+		{"YPF", "YPF"},
+		{"YPF FULL PALERMO", "YPF Full Palermo"},
+		{"ypf", "Ypf"},
+		// Mixed case is kept, whitespace and trailing punctuation are cleaned
+		{"  Starbucks   Coffee. ", "Starbucks Coffee"},
+		{"McDonald's", "McDonald's"},
+		// A bare prefix is not stripped to nothing
+		{"DLO*", "DLO"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.raw); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	for _, variants := range [][]string{
+		{"Payu*ar*uber", "PAYU*AR*UBER", "uber", "Uber."},
+		{"UBER TRIP", "Uber  Trip", "uber-trip"},
+		{"Merpago*meli", "MELI"},
+	} {
+		want := Key(variants[0])
+		for _, v := range variants[1:] {
+			if got := Key(v); got != want {
+				t.Errorf("Key(%q) = %q, want %q like %q", v, got, want, variants[0])
+			}
+		}
+	}
+	if Key("Uber") == Key("Uber Eats") {
+		t.Error("Uber and Uber Eats share a key")
+	}
+}
+
+func TestAliasesApply(t *testing.T) {
+	aliases := NewAliases([]models.MerchantAlias{
+		{Alias: "UBER TRIP", Canonical: "Uber"},
+		{Alias: "Payu*ar*uber", Canonical: "Uber Eats"},
+	})
+	if got := aliases.Canonical("uber trip"); got != "Uber" {
+		t.Errorf("Canonical(uber trip) = %q, want Uber", got)
+	}
+	if got := aliases.Canonical("Dlo*didi"); got != "Didi" {
+		t.Errorf("Canonical(Dlo*didi) = %q, want the normalized Didi", got)
+	}
+
+	raw := "PAYU*AR*UBER"
+	tx := models.Transaction{Merchant: &raw}
+	if !Apply(&tx) || *tx.Merchant != "Uber" || *tx.RawMerchant != raw {
+		t.Fatalf("Apply = %q from %v, want Uber from %s", *tx.Merchant, tx.RawMerchant, raw)
+	}
+
+	// Re-applying with the user's aliases starts from the raw merchant and keeps it
+	if !aliases.Apply(&tx) || *tx.Merchant != "Uber Eats" || *tx.RawMerchant != raw {
+		t.Errorf("re-apply = %q from %v, want Uber Eats from %s", *tx.Merchant, tx.RawMerchant, raw)
+	}
+	if aliases.Apply(&tx) {
+		t.Error("applying the same aliases again reported a change")
+	}
+
+	// Merchants edited by the user are left alone
+	edited := "Uber (work)"
+	locked := models.Transaction{Merchant: &edited, RawMerchant: &raw, MerchantLocked: true}
+	if aliases.Apply(&locked) || *locked.Merchant != edited {
+		t.Errorf("locked merchant changed to %q", *locked.Merchant)
+	}
+
+	blank := " "
+	for _, tx := range []models.Transaction{{}, {Merchant: &blank}} {
+		if Apply(&tx) || tx.RawMerchant != nil {
+			t.Errorf("Apply on merchant %v set %v", tx.Merchant, tx.RawMerchant)
+		}
+	}
+}
@@ -1,72 +1,332 @@
 package processor
 
 import (
+	"fmt"
+	"log"
 	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/fx"
 	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
 )
 
-// NeutralizeTransfers mirrors the Python logic to find matching internal transfers
-func NeutralizeTransfers(transactions []models.Transaction) []models.Transaction {
-	if len(transactions) == 0 {
-		return transactions
+// MatchOptions tunes how debits and credits are paired as internal transfers
+type MatchOptions struct {
+	AmountTolerance float64 // relative amount difference allowed for same-currency pairs
+	FXSpread        float64 // relative difference allowed after converting a cross-currency pair
+	DaysBefore      int     // how many days the credit may precede the debit
+	DaysAfter       int     // how many days the credit may follow the debit
+}
+
+// DefaultMatchOptions matches amounts within ±0.5% from 1 day before to 3 days
+// after the debit. Cross-currency pairs allow a 3% spread around the FX rate,
+// overridable with FX_SPREAD_TOLERANCE (e.g. 0.05).
+func DefaultMatchOptions() MatchOptions {
+	opts := MatchOptions{AmountTolerance: 0.005, FXSpread: 0.03, DaysBefore: 1, DaysAfter: 3}
+	if v := os.Getenv("FX_SPREAD_TOLERANCE"); v != "" {
+		if spread, err := strconv.ParseFloat(v, 64); err == nil && spread >= 0 {
+			opts.FXSpread = spread
+		} else {
+			log.Printf("neutralizer: ignoring FX_SPREAD_TOLERANCE=%q", v)
+		}
+	}
+	return opts
+}
+
+// MatchOptionsFor returns the default match options overridden by the
+// user's transfer settings
+func MatchOptionsFor(userID uuid.UUID) MatchOptions {
+	opts := DefaultMatchOptions()
+	if userID == uuid.Nil {
+		return opts
+	}
+	s, err := db.GetDB().GetTransferSettings(userID)
+	if err != nil {
+		return opts
+	}
+	if s.AmountTolerance != nil {
+		opts.AmountTolerance = *s.AmountTolerance
+	}
+	if s.FXSpread != nil {
+		opts.FXSpread = *s.FXSpread
 	}
+	if s.DaysBefore != nil {
+		opts.DaysBefore = *s.DaysBefore
+	}
+	if s.DaysAfter != nil {
+		opts.DaysAfter = *s.DaysAfter
+	}
+	return opts
+}
 
-	// Group transfers by amount and date
-	var debits []int
-	var credits []int
+// NeutralizeTransfers finds the debits and credits marked as transfers that are
+// two sides of one internal transfer, and the bank debits that pay a credit
+// card together with the payment on the card statement. Both sides of a pair
+// are neutralized so the money is not counted twice; the card purchases the
+// payment settles remain expenses. Same-currency pairs must have similar
+// amounts; cross-currency pairs are compared after converting the debit with
+// the rate for its date from rates.
+//
+// Every candidate pair is scored (see MatchPair) and pairs are chosen as a
+// whole: as many transfers as possible are matched and, among those choices,
+// the one with the highest total score wins, so a debit never takes a credit
+// that fits another debit better.
+//
+// links are the user's stored transfer links: transactions in an active link are
+// neutralized without being matched again, rejected pairs are never matched, and
+// account pairs linked before are scored as more plausible.
+// It returns the new auto links found.
+func NeutralizeTransfers(transactions []models.Transaction, links []models.TransferLink, opts MatchOptions, rates *fx.Table) ([]models.Transaction, []models.TransferLink) {
+	if len(transactions) == 0 {
+		return transactions, nil
+	}
 
+	byID := make(map[string]int, len(transactions))
 	for i, tx := range transactions {
-		if tx.IsTransfer {
-			if tx.Amount < 0 {
-				debits = append(debits, i)
-			} else if tx.Amount > 0 {
-				credits = append(credits, i)
+		byID[tx.ID] = i
+	}
+
+	m := &matcher{opts: opts, rates: rates, known: make(map[[2]string]bool)}
+	neutralizedIDs := make(map[string]bool)
+	kinds := make(map[string]string)
+	rejected := make(map[[2]string]bool)
+	for _, l := range links {
+		if l.Method == models.LinkRejected {
+			rejected[[2]string{l.DebitID, l.CreditID}] = true
+			continue
+		}
+		neutralizedIDs[l.DebitID] = true
+		neutralizedIDs[l.CreditID] = true
+		kinds[l.DebitID], kinds[l.CreditID] = linkKind(l), linkKind(l)
+		if l.DebitAccount != "" && l.CreditAccount != "" {
+			m.known[[2]string{l.DebitAccount, l.CreditAccount}] = true
+		} else if d, okD := byID[l.DebitID]; okD {
+			if c, okC := byID[l.CreditID]; okC {
+				m.known[[2]string{accountOf(transactions[d]), accountOf(transactions[c])}] = true
 			}
 		}
 	}
 
-	neutralizedIDs := make(map[string]bool)
+	// Transfers and card payments are matched as separate pools
+	var debits, credits, cardDebits, cardCredits []int
+	for i, tx := range transactions {
+		if neutralizedIDs[tx.ID] {
+			markLinked(&transactions[i], kinds[tx.ID], "linked "+strings.Replace(kinds[tx.ID], "_", " ", 1))
+			continue
+		}
+		switch {
+		case tx.DuplicateOf != nil:
+			continue
+		case tx.IsCardPayment && tx.Amount < 0:
+			cardDebits = append(cardDebits, i)
+		case tx.IsCardPayment && tx.Amount > 0:
+			cardCredits = append(cardCredits, i)
+		case tx.IsTransfer && tx.Amount < 0:
+			debits = append(debits, i)
+		case tx.IsTransfer && tx.Amount > 0:
+			credits = append(credits, i)
+		}
+	}
 
-	for _, dIdx := range debits {
-		txD := transactions[dIdx]
-		if neutralizedIDs[txD.ID] {
+	var candidates []candidate
+	for _, pool := range [][2][]int{{debits, credits}, {cardDebits, cardCredits}} {
+		if len(pool[0]) == 0 || len(pool[1]) == 0 {
 			continue
 		}
+		// Candidate pairs, looked up through the credit index instead of
+		// comparing every debit with every credit
+		index := newCreditIndex(transactions, pool[1])
+		for _, d := range pool[0] {
+			for _, c := range index.near(transactions[d], opts, rates) {
+				if rejected[[2]string{transactions[d].ID, transactions[c].ID}] {
+					continue
+				}
+				if link, ok := m.match(transactions[d], transactions[c]); ok {
+					candidates = append(candidates, candidate{debit: d, credit: c, link: link})
+				}
+			}
+		}
+	}
 
-		targetAmount := math.Abs(txD.Amount)
-		dateD, _ := time.Parse("2006-01-02", txD.Date)
+	chosen := bestMatching(candidates)
+	sort.Slice(chosen, func(i, j int) bool { return chosen[i].debit < chosen[j].debit })
 
-		for _, cIdx := range credits {
-			txC := transactions[cIdx]
-			if neutralizedIDs[txC.ID] {
-				continue
-			}
+	found := make([]models.TransferLink, 0, len(chosen))
+	for _, p := range chosen {
+		found = append(found, p.link)
+		markLinked(&transactions[p.debit], p.link.Kind, describeLink(p.link, transactions[p.credit].ID))
+		markLinked(&transactions[p.credit], p.link.Kind, describeLink(p.link, transactions[p.debit].ID))
+	}
+	return transactions, found
+}
 
-			// Similar amount (+/- 0.5%)
-			if math.Abs(txC.Amount) >= targetAmount*0.995 && math.Abs(txC.Amount) <= targetAmount*1.005 {
-				dateC, _ := time.Parse("2006-01-02", txC.Date)
+// MatchPair checks whether a debit and a credit look like the two sides of one
+// transfer and returns the auto link with its score
+func MatchPair(debit, credit models.Transaction, opts MatchOptions, rates *fx.Table) (models.TransferLink, bool) {
+	m := &matcher{opts: opts, rates: rates}
+	return m.match(debit, credit)
+}
 
-				// Window: 1 day before to 3 days after
-				diff := dateC.Sub(dateD).Hours() / 24
-				if diff >= -1 && diff <= 3 {
-					neutralizedIDs[txD.ID] = true
-					neutralizedIDs[txC.ID] = true
+// matcher scores candidate pairs. known holds the account pairs (debit account,
+// credit account) the user's transfers were linked between before.
+type matcher struct {
+	opts  MatchOptions
+	rates *fx.Table
+	known map[[2]string]bool
+}
 
-					transactions[dIdx].Neutralized = true
-					transactions[dIdx].Category = stringPtr("transferencia_interna")
+// match scores a pair within the allowed tolerances. Scores weigh the amount
+// difference (60%), the date distance (25%) and how plausible a transfer
+// between the two accounts is (15%).
+func (m *matcher) match(debit, credit models.Transaction) (models.TransferLink, bool) {
+	link := models.TransferLink{
+		DebitID: debit.ID, CreditID: credit.ID, DebitAccount: accountOf(debit), CreditAccount: accountOf(credit),
+		Kind: models.KindTransfer, Method: models.LinkAuto,
+	}
+	if debit.IsCardPayment || credit.IsCardPayment {
+		link.Kind = models.KindCardPayment
+	}
 
-					transactions[cIdx].Neutralized = true
-					transactions[cIdx].Category = stringPtr("transferencia_interna")
+	target, tolerance, rate, ok := m.target(debit, credit.Currency)
+	if !ok || target == 0 {
+		return link, false
+	}
+	if rate != nil {
+		link.FXRate = &rate.Rate
+		link.FXRateDate = rate.Date
+	}
+	delta := (credit.Amount.Abs() - target).Abs().Float64() / target.Float64()
+	if delta > tolerance {
+		return link, false
+	}
 
-					break // Found a match for this debit
-				}
-			}
-		}
+	// Window: by default 1 day before to 3 days after
+	dateD, _ := time.Parse("2006-01-02", dayOf(debit.Date))
+	dateC, _ := time.Parse("2006-01-02", dayOf(credit.Date))
+	diff := dateC.Sub(dateD).Hours() / 24
+	if diff < -float64(m.opts.DaysBefore) || diff > float64(m.opts.DaysAfter) {
+		return link, false
+	}
+
+	amountScore := 1.0
+	if tolerance > 0 {
+		amountScore = 1 - delta/tolerance
+	}
+	window := float64(m.opts.DaysAfter)
+	if diff < 0 {
+		window = float64(m.opts.DaysBefore)
+	}
+	dateScore := 1.0
+	if window > 0 {
+		dateScore = 1 - math.Abs(diff)/(window+1)
+	}
+	accountScore := m.plausibility(debit, credit)
+	link.Score = math.Round((0.6*amountScore+0.25*dateScore+0.15*accountScore)*100) / 100
+	return link, true
+}
+
+// target returns the amount a credit in currency should have to match debit,
+// with the tolerance that applies and the FX rate used, if any
+func (m *matcher) target(debit models.Transaction, currency string) (money.Amount, float64, *fx.Rate, bool) {
+	amount := debit.Amount.Abs()
+	if sameCurrency(debit.Currency, currency) {
+		return amount, m.opts.AmountTolerance, nil, true
+	}
+	rate, ok := m.rates.Lookup(dayOf(debit.Date), debit.Currency, currency)
+	if !ok {
+		return 0, 0, nil, false
+	}
+	return amount.Mul(rate.Rate), m.opts.FXSpread, &rate, true
+}
+
+// plausibility rates how likely a transfer between the two accounts is: money
+// rarely moves within one account, and accounts linked before are the most
+// likely pairs
+func (m *matcher) plausibility(debit, credit models.Transaction) float64 {
+	from, to := accountOf(debit), accountOf(credit)
+	switch {
+	case m.known[[2]string{from, to}]:
+		return 1
+	case from == to:
+		return 0.2
+	default:
+		return 0.6
+	}
+}
+
+// accountOf identifies the account of tx, by the user's account when resolved
+func accountOf(tx models.Transaction) string {
+	if tx.AccountID != nil {
+		return tx.AccountID.String()
+	}
+	return tx.Source + "/" + tx.Account
+}
+
+// markLinked neutralizes one side of an internal transfer or card payment,
+// keeping the classification it replaces so unmarkLinked can restore it
+func markLinked(tx *models.Transaction, kind, matched string) {
+	replaced := &models.ReplacedClassification{Category: tx.Category, Subcategory: tx.Subcategory, Provenance: tx.Provenance}
+	if tx.Provenance != nil && tx.Provenance.Stage == classifier.StageTransfer {
+		replaced = tx.Provenance.Replaced
+	}
+	tx.Neutralized = true
+	tx.Category = stringPtr("transferencia_interna")
+	if kind == models.KindCardPayment {
+		tx.Category = stringPtr("pago_tarjeta")
 	}
+	tx.Provenance = classifier.Provenance(classifier.StageTransfer, matched)
+	tx.Provenance.Replaced = replaced
+}
+
+// unmarkLinked undoes markLinked, restoring the classification tx had before
+// it was linked. Rows linked before that was recorded lose their category.
+func unmarkLinked(tx *models.Transaction) {
+	tx.Neutralized = false
+	if tx.Provenance == nil || tx.Provenance.Stage != classifier.StageTransfer {
+		return
+	}
+	replaced := tx.Provenance.Replaced
+	if replaced == nil {
+		replaced = &models.ReplacedClassification{}
+	}
+	tx.Category, tx.Subcategory, tx.Provenance = replaced.Category, replaced.Subcategory, replaced.Provenance
+}
 
-	return transactions
+// linkKind treats links stored before kinds existed as transfers
+func linkKind(l models.TransferLink) string {
+	if l.Kind == "" {
+		return models.KindTransfer
+	}
+	return l.Kind
+}
+
+// sameCurrency treats a missing currency as matching any other, like before
+// currencies were compared
+func sameCurrency(a, b string) bool {
+	return a == "" || b == "" || strings.EqualFold(a, b)
+}
+
+func describeLink(l models.TransferLink, counterpartID string) string {
+	if l.FXRate == nil {
+		return "matched " + counterpartID
+	}
+	return fmt.Sprintf("matched %s at rate %g (%s)", counterpartID, *l.FXRate, l.FXRateDate)
+}
+
+// dayOf trims a stored date such as 2024-01-02T00:00:00Z to YYYY-MM-DD
+func dayOf(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
 }
 
 func stringPtr(s string) *string {
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/fx"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+// Account pairs linked before count as plausible even when the linked
+// transactions are outside the rows being neutralized
+func TestNeutralizeTransfersKnownAccountsFromLinks(t *testing.T) {
+	batch := func() []models.Transaction {
+		return []models.Transaction{
+			{ID: "debit", Source: "galicia", Account: "cuenta_corriente", Date: "2024-06-03", Amount: money.Amount(-50000), Currency: "ARS", IsTransfer: true},
+			{ID: "credit", Source: "brubank", Account: "caja_ahorro_pesos", Date: "2024-06-03", Amount: money.Amount(50000), Currency: "ARS", IsTransfer: true},
+		}
+	}
+	old := models.TransferLink{
+		DebitID: "old-debit", CreditID: "old-credit", Method: models.LinkAuto, Kind: models.KindTransfer,
+		DebitAccount: "galicia/cuenta_corriente", CreditAccount: "brubank/caja_ahorro_pesos",
+	}
+
+	_, unknown := NeutralizeTransfers(batch(), nil, DefaultMatchOptions(), fx.Default())
+	_, known := NeutralizeTransfers(batch(), []models.TransferLink{old}, DefaultMatchOptions(), fx.Default())
+	if len(unknown) != 1 || len(known) != 1 {
+		t.Fatalf("found %d and %d links, want 1 each", len(unknown), len(known))
+	}
+	if known[0].Score != 1 || unknown[0].Score >= known[0].Score {
+		t.Errorf("score = %v with the accounts linked before and %v without, want 1 and less", known[0].Score, unknown[0].Score)
+	}
+	if known[0].DebitAccount != "galicia/cuenta_corriente" || known[0].CreditAccount != "brubank/caja_ahorro_pesos" {
+		t.Errorf("link accounts = %q, %q", known[0].DebitAccount, known[0].CreditAccount)
+	}
+}
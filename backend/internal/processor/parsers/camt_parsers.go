@@ -0,0 +1,303 @@
+package parsers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+// CamtParser reads ISO 20022 camt.053 (end of day statement) and camt.052
+// (intraday account report) XML files. Element names are matched without
+// namespaces so every message version is accepted.
+type CamtParser struct {
+	statements []models.Statement
+}
+
+type camtDocument struct {
+	Stmts []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+	Rpts  []camtStatement `xml:"BkToCstmrAcctRpt>Rpt"`
+}
+
+type camtStatement struct {
+	IBAN    string        `xml:"Acct>Id>IBAN"`
+	OtherID string        `xml:"Acct>Id>Othr>Id"`
+	Ccy     string        `xml:"Acct>Ccy"`
+	Bal     []camtBalance `xml:"Bal"`
+	Ntry    []camtEntry   `xml:"Ntry"`
+}
+
+type camtBalance struct {
+	Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amt       camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Dt        camtDate   `xml:"Dt"`
+}
+
+type camtAmount struct {
+	Value string `xml:",chardata"`
+	Ccy   string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Dt   string `xml:"Dt"`
+	DtTm string `xml:"DtTm"`
+}
+
+type camtEntry struct {
+	NtryRef      string          `xml:"NtryRef"`
+	Amt          camtAmount      `xml:"Amt"`
+	CdtDbtInd    string          `xml:"CdtDbtInd"`
+	BookgDt      camtDate        `xml:"BookgDt"`
+	ValDt        camtDate        `xml:"ValDt"`
+	AcctSvcrRef  string          `xml:"AcctSvcrRef"`
+	AddtlNtryInf string          `xml:"AddtlNtryInf"`
+	TxDtls       []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTxDetails struct {
+	EndToEndID  string     `xml:"Refs>EndToEndId"`
+	AcctSvcrRef string     `xml:"Refs>AcctSvcrRef"`
+	Amt         camtAmount `xml:"Amt"`
+	TxAmt       camtAmount `xml:"AmtDtls>TxAmt>Amt"` // 001.02 has no Amt of its own
+	Dbtr        camtParty  `xml:"RltdPties>Dbtr"`
+	Cdtr        camtParty  `xml:"RltdPties>Cdtr"`
+	Ustrd       []string   `xml:"RmtInf>Ustrd"`
+	AddtlTxInf  string     `xml:"AddtlTxInf"`
+}
+
+// camtParty covers both the flat (001.02) and Pty-wrapped (001.08+) layouts
+type camtParty struct {
+	Nm    string `xml:"Nm"`
+	PtyNm string `xml:"Pty>Nm"`
+}
+
+// amount is the transaction amount, found under AmtDtls in camt.053.001.02
+func (d camtTxDetails) amount() camtAmount {
+	if d.Amt.Value != "" {
+		return d.Amt
+	}
+	return d.TxAmt
+}
+
+func (p camtParty) name() string {
+	if p.Nm != "" {
+		return strings.TrimSpace(p.Nm)
+	}
+	return strings.TrimSpace(p.PtyNm)
+}
+
+func (d camtDate) iso() string {
+	v := d.Dt
+	if v == "" {
+		v = d.DtTm
+	}
+	if len(v) < 10 {
+		return ""
+	}
+	return v[:10]
+}
+
+func (p *CamtParser) Normalize(filePath string) ([]models.Transaction, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc camtDocument
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	stmts := append(doc.Stmts, doc.Rpts...)
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("could not find camt.053/camt.052 statement")
+	}
+
+	p.statements = nil
+	var transactions []models.Transaction
+
+	for _, stmt := range stmts {
+		account := stmt.IBAN
+		if account == "" {
+			account = stmt.OtherID
+		}
+		p.statements = append(p.statements, camtStatementSummary(account, stmt))
+
+		for _, ntry := range stmt.Ntry {
+			// Batch bookings carry one TxDtls per movement, each with its own amount
+			details := ntry.TxDtls
+			split := len(details) > 1
+			for _, d := range details {
+				if d.amount().Value == "" {
+					split = false
+				}
+			}
+			if !split {
+				var first camtTxDetails
+				if len(details) > 0 {
+					first = details[0]
+				}
+				details = []camtTxDetails{first}
+			}
+
+			for _, d := range details {
+				amt := ntry.Amt
+				if split {
+					amt = d.amount()
+				}
+				if tx, ok := camtTransaction(account, stmt.Ccy, ntry, d, amt); ok {
+					transactions = append(transactions, tx)
+				}
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+func camtTransaction(account, acctCcy string, ntry camtEntry, d camtTxDetails, amt camtAmount) (models.Transaction, bool) {
+	amount, err := money.Parse(amt.Value)
+	if err != nil {
+		return models.Transaction{}, false
+	}
+	isDebit := ntry.CdtDbtInd == "DBIT"
+	if isDebit {
+		amount = -amount
+	}
+
+	bookingDate := ntry.BookgDt.iso()
+	valueDate := ntry.ValDt.iso()
+	if bookingDate == "" {
+		bookingDate = valueDate
+	}
+	if bookingDate == "" {
+		return models.Transaction{}, false
+	}
+
+	currency := amt.Ccy
+	if currency == "" {
+		currency = acctCcy
+	}
+
+	counterparty := d.Cdtr.name()
+	if !isDebit {
+		counterparty = d.Dbtr.name()
+	}
+
+	description := strings.TrimSpace(strings.Join(d.Ustrd, " "))
+	if description == "" {
+		description = strings.TrimSpace(d.AddtlTxInf)
+	}
+	if description == "" {
+		description = strings.TrimSpace(ntry.AddtlNtryInf)
+	}
+	if counterparty != "" && !strings.Contains(description, counterparty) {
+		description = strings.TrimSpace(counterparty + " " + description)
+	}
+
+	ref := firstReference(d.AcctSvcrRef, d.EndToEndID, ntry.AcctSvcrRef, ntry.NtryRef)
+	if ref == "" {
+		ref = description
+	}
+
+	direction := "debit"
+	if amount > 0 {
+		direction = "credit"
+	}
+
+	isTax := containsAny(description, "iva", "percepción", "impuesto", "tax", "steuer")
+	isTransfer := containsAny(description, "transferencia", "transfer", "überweisung")
+	isFee := containsAny(description, "comisión", "comision", "fee", "gebühr", "entgelt")
+
+	var merchantPtr *string
+	if counterparty != "" {
+		merchantPtr = &counterparty
+	}
+
+	tx := models.Transaction{
+		ID:          common.GenerateID("camt", account, bookingDate, amount.String(), "ref:"+ref),
+		Source:      "camt",
+		Account:     account,
+		Date:        bookingDate,
+		ValueDate:   valueDate,
+		Amount:      amount,
+		Currency:    currency,
+		Description: description,
+		Direction:   direction,
+		Merchant:    merchantPtr,
+		IsTransfer:  isTransfer,
+		IsFee:       isFee,
+		IsTax:       isTax,
+	}
+	classify(&tx)
+	return tx, true
+}
+
+// camtStatementSummary picks the opening and closing balances of a statement.
+// camt.053 uses OPBD/CLBD; intraday camt.052 reports fall back to ITBD/CLAV.
+func camtStatementSummary(account string, stmt camtStatement) models.Statement {
+	statement := models.Statement{Source: "camt", Account: account, Currency: stmt.Ccy}
+	for _, bal := range stmt.Bal {
+		amount, err := money.Parse(bal.Amt.Value)
+		if err != nil {
+			continue
+		}
+		if bal.CdtDbtInd == "DBIT" {
+			amount = -amount
+		}
+		if statement.Currency == "" {
+			statement.Currency = bal.Amt.Ccy
+		}
+
+		switch bal.Code {
+		case "OPBD", "PRCD":
+			if statement.OpeningBalance == nil || bal.Code == "OPBD" {
+				statement.OpeningBalance = &amount
+				statement.OpeningDate = bal.Dt.iso()
+			}
+		case "CLBD", "ITBD", "CLAV":
+			if statement.ClosingBalance == nil || bal.Code == "CLBD" {
+				statement.ClosingBalance = &amount
+				statement.ClosingDate = bal.Dt.iso()
+			}
+		}
+	}
+	return statement
+}
+
+func (p *CamtParser) Statements() []models.Statement {
+	return p.statements
+}
+
+func (p *CamtParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "text" {
+		return s.result()
+	}
+	if strings.Contains(sample.Text, "camt.053") || strings.Contains(sample.Text, "camt.052") {
+		s.add(0.6, "ISO 20022 camt namespace")
+	}
+	if strings.Contains(sample.Text, "BkToCstmrStmt") || strings.Contains(sample.Text, "BkToCstmrAcctRpt") {
+		s.add(0.3, "BkToCstmrStmt/BkToCstmrAcctRpt message")
+	}
+	if strings.Contains(sample.Text, "<Ntry>") {
+		s.add(0.1, "contains Ntry entries")
+	}
+	return s.result()
+}
+
+// firstReference returns the first reference the bank actually supplied;
+// NOTPROVIDED is the placeholder for a missing EndToEndId
+func firstReference(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" && v != "NOTPROVIDED" {
+			return v
+		}
+	}
+	return ""
+}
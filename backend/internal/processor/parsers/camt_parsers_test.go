@@ -0,0 +1,211 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+const camt053v08 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>STMT-1</Id>
+      <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>PRCD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">900.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-02-29</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-03-01</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">25.50</Amt><CdtDbtInd>DBIT</CdtDbtInd><Dt><Dt>2024-03-31</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="EUR">300.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-04</Dt></BookgDt>
+        <ValDt><Dt>2024-03-05</Dt></ValDt>
+        <AcctSvcrRef>BATCH-1</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>E2E-1</EndToEndId></Refs>
+            <Amt Ccy="EUR">100.00</Amt>
+            <RltdPties><Cdtr><Pty><Nm>Stadtwerke Berlin</Nm></Pty></Cdtr></RltdPties>
+            <RmtInf><Ustrd>Strom Maerz</Ustrd></RmtInf>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>E2E-2</EndToEndId></Refs>
+            <Amt Ccy="EUR">200.00</Amt>
+            <RltdPties><Cdtr><Pty><Nm>Hausverwaltung Meyer</Nm></Pty></Cdtr></RltdPties>
+            <RmtInf><Ustrd>Miete Maerz</Ustrd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">1500.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-25</Dt></BookgDt>
+        <AcctSvcrRef>BANKREF-7</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+            <RltdPties><Dbtr><Pty><Nm>Acme GmbH</Nm></Pty></Dbtr></RltdPties>
+            <RmtInf><Ustrd>Gehalt</Ustrd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func TestCamtParserBatchAndBalances(t *testing.T) {
+	p := &CamtParser{}
+	txs, err := p.Normalize(writeFixture(t, "camt053.xml", camt053v08))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 3 {
+		t.Fatalf("got %d transactions, want 3: %+v", len(txs), txs)
+	}
+
+	// The batch booking is split into its two payments
+	for i, want := range []struct {
+		amount      money.Amount
+		payee, text string
+	}{
+		{-10000, "Stadtwerke Berlin", "Stadtwerke Berlin Strom Maerz"},
+		{-20000, "Hausverwaltung Meyer", "Hausverwaltung Meyer Miete Maerz"},
+	} {
+		tx := txs[i]
+		if tx.Amount != want.amount || tx.Description != want.text || tx.RawMerchant == nil || *tx.RawMerchant != want.payee {
+			t.Errorf("batch row %d = %s %q merchant %v, want %s %q %s", i, tx.Amount, tx.Description, tx.RawMerchant, want.amount, want.text, want.payee)
+		}
+		if tx.Date != "2024-03-04" || tx.ValueDate != "2024-03-05" || tx.Currency != "EUR" || tx.Account != "DE89370400440532013000" {
+			t.Errorf("batch row %d = %s/%s %s %s", i, tx.Date, tx.ValueDate, tx.Currency, tx.Account)
+		}
+	}
+	if txs[0].ID == txs[1].ID {
+		t.Error("batch rows share an ID")
+	}
+
+	// NOTPROVIDED is skipped in favour of the entry's bank reference
+	salary := txs[2]
+	want := common.GenerateID("camt", "DE89370400440532013000", "2024-03-25", "1500.00", "ref:BANKREF-7")
+	if salary.ID != want || salary.Amount != 150000 || salary.Direction != "credit" {
+		t.Errorf("salary = %s %s %s, want ID %s from BANKREF-7", salary.ID, salary.Amount, salary.Direction, want)
+	}
+
+	st := p.Statements()
+	if len(st) != 1 {
+		t.Fatalf("got %d statements, want 1", len(st))
+	}
+	assertBalances(t, st[0], 100000, "2024-03-01", -2550, "2024-03-31")
+}
+
+const camt053v02 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Acct><Id><Othr><Id>0532013000</Id></Othr></Id><Ccy>EUR</Ccy></Acct>
+      <Ntry>
+        <Amt Ccy="EUR">75.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2024-04-02</Dt></BookgDt>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>A</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">25.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><Cdtr><Nm>Baeckerei Schulz</Nm></Cdtr></RltdPties>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>B</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">50.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><Cdtr><Nm>Buchhandlung Lange</Nm></Cdtr></RltdPties>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+// camt.053.001.02 keeps the amounts of a batch under AmtDtls
+func TestCamtParserBatchV02(t *testing.T) {
+	txs, err := (&CamtParser{}).Normalize(writeFixture(t, "camt053v02.xml", camt053v02))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2: %+v", len(txs), txs)
+	}
+	if txs[0].Amount != -2500 || *txs[0].RawMerchant != "Baeckerei Schulz" || txs[1].Amount != -5000 || *txs[1].RawMerchant != "Buchhandlung Lange" {
+		t.Errorf("rows = %s %s, %s %s", txs[0].Amount, *txs[0].RawMerchant, txs[1].Amount, *txs[1].RawMerchant)
+	}
+	if txs[0].Account != "0532013000" {
+		t.Errorf("account = %q, want the Othr ID", txs[0].Account)
+	}
+}
+
+const camt052 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.052.001.08">
+  <BkToCstmrAcctRpt>
+    <Rpt>
+      <Acct><Id><IBAN>NL91ABNA0417164300</IBAN></Id></Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>ITBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">410.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><DtTm>2024-05-06T14:00:00</DtTm></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLAV</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">400.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-05-06</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="EUR">12.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><DtTm>2024-05-06T09:15:00</DtTm></BookgDt>
+        <NtryRef>N-1</NtryRef>
+        <AddtlNtryInf>KARTENZAHLUNG CAFE</AddtlNtryInf>
+      </Ntry>
+    </Rpt>
+  </BkToCstmrAcctRpt>
+</Document>
+`
+
+// Intraday reports have no CLBD; the first of ITBD and CLAV closes them
+func TestCamtParserIntraday(t *testing.T) {
+	p := &CamtParser{}
+	txs, err := p.Normalize(writeFixture(t, "camt052.xml", camt052))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].Date != "2024-05-06" || txs[0].Amount != -1200 || txs[0].Description != "KARTENZAHLUNG CAFE" {
+		t.Fatalf("transactions = %+v", txs)
+	}
+	if txs[0].ID != common.GenerateID("camt", "NL91ABNA0417164300", "2024-05-06", "-12.00", "ref:N-1") {
+		t.Errorf("ID not based on NtryRef")
+	}
+	st := p.Statements()
+	if len(st) != 1 || st[0].Currency != "EUR" || st[0].OpeningBalance != nil {
+		t.Fatalf("statements = %+v", st)
+	}
+	if st[0].ClosingBalance == nil || *st[0].ClosingBalance != 41000 || st[0].ClosingDate != "2024-05-06" {
+		t.Errorf("closing balance = %v on %q, want 410.00 from ITBD", st[0].ClosingBalance, st[0].ClosingDate)
+	}
+}
+
+func assertBalances(t *testing.T, st models.Statement, opening money.Amount, openingDate string, closing money.Amount, closingDate string) {
+	t.Helper()
+	if st.OpeningBalance == nil || *st.OpeningBalance != opening || st.OpeningDate != openingDate {
+		t.Errorf("opening balance = %v on %q, want %s on %s", st.OpeningBalance, st.OpeningDate, opening, openingDate)
+	}
+	if st.ClosingBalance == nil || *st.ClosingBalance != closing || st.ClosingDate != closingDate {
+		t.Errorf("closing balance = %v on %q, want %s on %s", st.ClosingBalance, st.ClosingDate, closing, closingDate)
+	}
+}
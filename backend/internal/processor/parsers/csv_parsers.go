@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
 	"github.com/juank/finance-ai/backend/internal/processor/common"
+	"github.com/juank/finance-ai/backend/internal/processor/merchants"
 )
 
 type MercadoPagoParser struct{}
@@ -97,8 +99,6 @@ func (p *MercadoPagoParser) Normalize(filePath string) ([]models.Transaction, er
 		isTransfer := containsAny(description, "transferencia", "enviaste", "recibiste", "de una cuenta tuya", "a una cuenta tuya")
 		isFee := strings.Contains(strings.ToLower(description), "comisión")
 
-		cat, sub := common.InferCategory(description)
-
 		merchant := ""
 		if strings.HasPrefix(description, "Pago ") {
 			merchant = strings.Split(strings.TrimPrefix(description, "Pago "), " ")[0]
@@ -106,15 +106,13 @@ func (p *MercadoPagoParser) Normalize(filePath string) ([]models.Transaction, er
 			merchant = strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(description, "Transferencia ", ""), "enviada ", ""), "recibida ", "")
 		}
 
-		var merchantPtr, catPtr, subPtr *string
+		var merchantPtr *string
 		if merchant != "" {
 			merchantPtr = &merchant
 		}
-		catPtr = cat
-		subPtr = sub
 
-		transactions = append(transactions, models.Transaction{
-			ID:          common.GenerateID("mercadopago", "cuenta_digital", dateISO, fmt.Sprintf("%.2f", amount), description),
+		tx := models.Transaction{
+			ID:          common.GenerateID("mercadopago", "cuenta_digital", dateISO, amount.String(), description),
 			Source:      "mercadopago",
 			Account:     "cuenta_digital",
 			Date:        dateISO,
@@ -123,18 +121,37 @@ func (p *MercadoPagoParser) Normalize(filePath string) ([]models.Transaction, er
 			Description: description,
 			Direction:   direction,
 			Merchant:    merchantPtr,
-			Category:    catPtr,
-			Subcategory: subPtr,
 			Balance:     &balance,
 			IsTransfer:  isTransfer,
 			IsFee:       isFee,
 			IsTax:       isTax,
-		})
+		}
+		classify(&tx)
+		transactions = append(transactions, tx)
 	}
 
 	return transactions, nil
 }
 
+func (p *MercadoPagoParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "text" {
+		return s.result()
+	}
+	header := headerLine(sample, "RELEASE_DATE")
+	if header == "" {
+		return s.result()
+	}
+	s.add(0.6, "CSV header has RELEASE_DATE")
+	if strings.Contains(header, "TRANSACTION_NET_AMOUNT") {
+		s.add(0.2, "CSV header has TRANSACTION_NET_AMOUNT")
+	}
+	if strings.Contains(header, "PARTIAL_BALANCE") {
+		s.add(0.1, "CSV header has PARTIAL_BALANCE")
+	}
+	return s.result()
+}
+
 type DeelParser struct{}
 
 func (p *DeelParser) Normalize(filePath string) ([]models.Transaction, error) {
@@ -173,7 +190,7 @@ func (p *DeelParser) Normalize(filePath string) ([]models.Transaction, error) {
 		dateISO := strings.Split(dateVal, " ")[0]
 
 		amountStr := row[colMap["Transaction Amount"]]
-		amount, _ := strconv.ParseFloat(amountStr, 64)
+		amount, _ := money.Parse(amountStr)
 		currency := row[colMap["Currency"]]
 		txType := row[colMap["Transaction Type"]]
 
@@ -190,19 +207,13 @@ func (p *DeelParser) Normalize(filePath string) ([]models.Transaction, error) {
 		isTransfer := txType == "withdrawal" || txType == "deel_card_withdrawal"
 		isFee := strings.Contains(strings.ToLower(description), "fee") || txType == "provider_fee"
 
-		cat, sub := common.InferCategory(description)
-		if cat == nil && txType == "client_payment" {
-			c, s := "ingresos", "sueldo"
-			cat, sub = &c, &s
-		}
-
 		var merchantPtr *string
 		if client != "" {
 			merchantPtr = &client
 		}
 
-		transactions = append(transactions, models.Transaction{
-			ID:          common.GenerateID("deel", "balance_usd", dateISO, fmt.Sprintf("%.2f", amount), description),
+		tx := models.Transaction{
+			ID:          common.GenerateID("deel", "balance_usd", dateISO, amount.String(), description),
 			Source:      "deel",
 			Account:     "balance_usd",
 			Date:        dateISO,
@@ -211,17 +222,88 @@ func (p *DeelParser) Normalize(filePath string) ([]models.Transaction, error) {
 			Description: description,
 			Direction:   direction,
 			Merchant:    merchantPtr,
-			Category:    cat,
-			Subcategory: sub,
 			IsTransfer:  isTransfer,
 			IsFee:       isFee,
 			IsTax:       isTax,
-		})
+		}
+		classify(&tx)
+		if tx.Category == nil && txType == "client_payment" {
+			c, s := "ingresos", "sueldo"
+			tx.Category, tx.Subcategory = &c, &s
+			tx.Provenance = classifier.Provenance(classifier.StageParser, "deel type=client_payment")
+		}
+		transactions = append(transactions, tx)
 	}
 
 	return transactions, nil
 }
 
+func (p *DeelParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "text" {
+		return s.result()
+	}
+	header := headerLine(sample, "Transaction Status")
+	if header == "" {
+		return s.result()
+	}
+	s.add(0.5, "CSV header has Transaction Status")
+	if strings.Contains(header, "Date Requested") {
+		s.add(0.2, "CSV header has Date Requested")
+	}
+	if strings.Contains(header, "Contract Name") {
+		s.add(0.2, "CSV header has Contract Name")
+	}
+	return s.result()
+}
+
+// classify flags card payment debits, normalizes the merchant of tx and
+// applies the global classification rules
+func classify(tx *models.Transaction) {
+	markCardPayment(tx)
+	merchants.Apply(tx)
+	classifier.Default().Classify(tx)
+}
+
+// classifyCardStatement is classify for the rows of a credit card statement,
+// where the payment is a credit and debits are purchases
+func classifyCardStatement(tx *models.Transaction) {
+	markStatementPayment(tx)
+	merchants.Apply(tx)
+	classifier.Default().Classify(tx)
+}
+
+// cardPaymentRegex matches the descriptions banks give the debit paying a
+// credit card: "PAGO TARJETA VISA", "Pago de tarjeta de crédito", "PAGO TC",
+// or a brand on its own as in "DEB. AUT. PAGO AMEX" or "PAGO VISA 4509". The
+// description must start with the payment, so purchases and other payments
+// that mention a card brand do not match.
+var cardPaymentRegex = regexp.MustCompile(`(?i)^(deb(ito)?\.? ?aut(om[aá]tico)?\.? )?(pago|pgo) (de )?(` +
+	`(tarjeta|tarj\.?|tc)(\s|$)|` +
+	`(visa|master(card)?|amex|american express|cabal|naranja)( (tarjeta|cr[eé]dito))?( [\d*x]+)?$)`)
+
+// statementPaymentRegex matches the payment credited on a card statement,
+// e.g. "SU PAGO EN PESOS"
+var statementPaymentRegex = regexp.MustCompile(`(?i)\bsu pago\b|\bpago en\b`)
+
+// markCardPayment flags a bank debit paying a credit card. It is not a
+// transfer: it is linked to the payment on the card statement instead.
+func markCardPayment(tx *models.Transaction) {
+	if tx.Amount < 0 && cardPaymentRegex.MatchString(strings.TrimSpace(tx.Description)) {
+		tx.IsCardPayment = true
+		tx.IsTransfer = false
+	}
+}
+
+// markStatementPayment flags the payment credited on a card statement, the
+// other side of the bank debit markCardPayment flags
+func markStatementPayment(tx *models.Transaction) {
+	if tx.Amount > 0 && statementPaymentRegex.MatchString(tx.Description) {
+		tx.IsCardPayment = true
+		tx.IsTransfer = false
+	}
+}
+
 func containsAny(s string, keywords ...string) bool {
 	lower := strings.ToLower(s)
 	for _, kw := range keywords {
@@ -231,8 +313,3 @@ func containsAny(s string, keywords ...string) bool {
 	}
 	return false
 }
-
-func strconvParseFloat(s string) float64 {
-	val, _ := strconv.ParseFloat(s, 64)
-	return val
-}
@@ -0,0 +1,59 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+func TestMarkCardPayment(t *testing.T) {
+	tests := []struct {
+		description string
+		amount      money.Amount
+		want        bool
+	}{
+		{"PAGO TARJETA VISA", -150000, true},
+		{"Pago de tarjeta de crédito", -150000, true},
+		{"PAGO TARJ. MASTER", -150000, true},
+		{"PAGO TC", -150000, true},
+		{"DEB. AUT. PAGO AMEX", -150000, true},
+		{"DEBITO AUTOMATICO PAGO VISA", -150000, true},
+		{"PAGO VISA 4509", -150000, true},
+		{"PAGO MASTERCARD", -150000, true},
+		{"PAGO TARJETA VISA", 150000, false},
+		{"COMPRA VISA DEBITO SUPERMERCADO DIA", -150000, false},
+		{"PAGO SERVICIO EDENOR VISA", -150000, false},
+		{"PAGO VISA DEBITO FARMACIA", -150000, false},
+		{"PAGO CON TARJETA DEBITO", -150000, false},
+		{"TRANSFERENCIA PAGO ALQUILER MASTER", -150000, false},
+	}
+	for _, tt := range tests {
+		tx := models.Transaction{Description: tt.description, Amount: tt.amount, IsTransfer: true}
+		markCardPayment(&tx)
+		if tx.IsCardPayment != tt.want || tx.IsTransfer == tt.want {
+			t.Errorf("markCardPayment(%q, %s): card payment = %v, transfer = %v, want card payment %v", tt.description, tt.amount, tx.IsCardPayment, tx.IsTransfer, tt.want)
+		}
+	}
+}
+
+func TestMarkStatementPayment(t *testing.T) {
+	tests := []struct {
+		description string
+		amount      money.Amount
+		want        bool
+	}{
+		{"SU PAGO EN PESOS", 150000, true},
+		{"PAGO EN USD", 5000, true},
+		{"SU PAGO EN PESOS", -150000, false},
+		{"MERPAGO*PAGO EN CUOTAS", -30000, false},
+		{"DEVOLUCION COMPRA", 20000, false},
+	}
+	for _, tt := range tests {
+		tx := models.Transaction{Description: tt.description, Amount: tt.amount}
+		markStatementPayment(&tx)
+		if tx.IsCardPayment != tt.want {
+			t.Errorf("markStatementPayment(%q, %s) = %v, want %v", tt.description, tt.amount, tx.IsCardPayment, tt.want)
+		}
+	}
+}
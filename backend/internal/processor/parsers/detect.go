@@ -0,0 +1,105 @@
+package parsers
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	sampleTextBytes = 64 << 10
+	sampleXLSXRows  = 40
+)
+
+// LoadSample reads just enough of a file to let the parsers detect its format.
+// The kind is decided by magic bytes, never by the filename.
+func LoadSample(filePath string) (*common.Sample, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, sampleTextBytes)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	sample := &common.Sample{Path: filePath}
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF")):
+		sample.Kind = "pdf"
+		text, err := readPDFText(filePath)
+		if err != nil {
+			return nil, err
+		}
+		sample.Text = text
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		sample.Kind = "xlsx"
+		rows, err := readXLSXHead(filePath, sampleXLSXRows)
+		if err != nil {
+			return nil, err
+		}
+		sample.Rows = rows
+	default:
+		sample.Kind = "text"
+		sample.Text = strings.TrimPrefix(string(head), "\ufeff")
+	}
+	return sample, nil
+}
+
+func readXLSXHead(filePath string, limit int) ([][]string, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// headerLine returns the first line of a text sample containing marker
+func headerLine(sample *common.Sample, marker string) string {
+	for _, line := range strings.Split(sample.Text, "\n") {
+		if strings.Contains(line, marker) {
+			return line
+		}
+	}
+	return ""
+}
+
+// scorer accumulates weighted evidence into a Detection
+type scorer struct {
+	score   float64
+	reasons []string
+}
+
+func (s *scorer) add(weight float64, reason string) {
+	s.score += weight
+	s.reasons = append(s.reasons, reason)
+}
+
+func (s *scorer) result() common.Detection {
+	if s.score > 1 {
+		s.score = 1
+	}
+	return common.Detection{Confidence: math.Round(s.score*100) / 100, Reason: strings.Join(s.reasons, "; ")}
+}
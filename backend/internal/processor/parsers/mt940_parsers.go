@@ -0,0 +1,294 @@
+package parsers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+// MT940Parser reads SWIFT MT940 customer statements
+type MT940Parser struct {
+	statements []models.Statement
+}
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+var (
+	mt940TagRegex     = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	mt940BalanceRegex = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})([\d,]+)`)
+	// :61: value date, optional entry date, mark, funds code, amount, type code, references
+	mt940LineRegex = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?([\d,]+)([NSF][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?`)
+)
+
+func (p *MT940Parser) Normalize(filePath string) ([]models.Transaction, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := splitMT940(string(raw))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("could not find MT940 fields")
+	}
+
+	p.statements = nil
+	var transactions []models.Transaction
+	var statement *models.Statement
+	account, currency := "", ""
+
+	for i, f := range fields {
+		switch f.tag {
+		case "20":
+			if statement != nil {
+				p.statements = append(p.statements, *statement)
+			}
+			statement = &models.Statement{Source: "mt940"}
+		case "25":
+			account = strings.TrimSpace(f.value)
+			if statement != nil {
+				statement.Account = account
+			}
+		case "60F", "60M":
+			if bal, date, ccy, ok := parseMT940Balance(f.value); ok && statement != nil {
+				currency = ccy
+				statement.Currency = ccy
+				statement.OpeningBalance = &bal
+				statement.OpeningDate = date
+			}
+		case "62F", "62M":
+			if bal, date, ccy, ok := parseMT940Balance(f.value); ok && statement != nil {
+				statement.Currency = ccy
+				statement.ClosingBalance = &bal
+				statement.ClosingDate = date
+			}
+		case "61":
+			info := ""
+			if i+1 < len(fields) && fields[i+1].tag == "86" {
+				info = fields[i+1].value
+			}
+			if tx, ok := mt940Transaction(account, currency, f.value, info); ok {
+				transactions = append(transactions, tx)
+			}
+		}
+	}
+	if statement != nil {
+		p.statements = append(p.statements, *statement)
+	}
+
+	return transactions, nil
+}
+
+func mt940Transaction(account, currency, line, info string) (models.Transaction, bool) {
+	m := mt940LineRegex.FindStringSubmatch(line)
+	if m == nil {
+		return models.Transaction{}, false
+	}
+
+	valueDate, ok := mt940Date(m[1])
+	if !ok {
+		return models.Transaction{}, false
+	}
+	bookingDate := valueDate
+	if m[2] != "" {
+		bookingDate = mt940EntryDate(valueDate, m[2])
+	}
+
+	amount, err := money.Parse(strings.ReplaceAll(m[5], ",", "."))
+	if err != nil {
+		return models.Transaction{}, false
+	}
+	// D and RC (reversal of credit) take money out of the account
+	if m[3] == "D" || m[3] == "RC" {
+		amount = -amount
+	}
+
+	counterparty, remittance := parseMT940Info(info)
+	description := remittance
+	if description == "" {
+		description = strings.TrimSpace(m[7])
+	}
+	if counterparty != "" && !strings.Contains(description, counterparty) {
+		description = strings.TrimSpace(counterparty + " " + description)
+	}
+
+	ref := strings.TrimSpace(m[8])
+	if ref == "" {
+		ref = strings.TrimSpace(m[7])
+	}
+	if ref == "" || ref == "NONREF" {
+		ref = description
+	}
+
+	direction := "debit"
+	if amount > 0 {
+		direction = "credit"
+	}
+
+	isTax := containsAny(description, "iva", "percepción", "impuesto", "tax", "steuer")
+	isTransfer := strings.HasSuffix(m[6], "TRF") || containsAny(description, "transferencia", "transfer", "überweisung")
+	isFee := strings.HasSuffix(m[6], "CHG") || containsAny(description, "comisión", "comision", "fee", "gebühr", "entgelt")
+
+	var merchantPtr *string
+	if counterparty != "" {
+		merchantPtr = &counterparty
+	}
+
+	tx := models.Transaction{
+		ID:          common.GenerateID("mt940", account, bookingDate, amount.String(), "ref:"+ref),
+		Source:      "mt940",
+		Account:     account,
+		Date:        bookingDate,
+		ValueDate:   valueDate,
+		Amount:      amount,
+		Currency:    currency,
+		Description: description,
+		Direction:   direction,
+		Merchant:    merchantPtr,
+		IsTransfer:  isTransfer,
+		IsFee:       isFee,
+		IsTax:       isTax,
+	}
+	classify(&tx)
+	return tx, true
+}
+
+// splitMT940 turns the message into tag/value fields, joining continuation lines
+func splitMT940(content string) []mt940Field {
+	var fields []mt940Field
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if m := mt940TagRegex.FindStringSubmatch(line); m != nil {
+			fields = append(fields, mt940Field{tag: m[1], value: m[2]})
+			continue
+		}
+		if line == "" || line == "-" || strings.HasPrefix(line, "-}") || strings.HasPrefix(line, "{") || len(fields) == 0 {
+			continue
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+	return fields
+}
+
+func parseMT940Balance(value string) (money.Amount, string, string, bool) {
+	m := mt940BalanceRegex.FindStringSubmatch(value)
+	if m == nil {
+		return 0, "", "", false
+	}
+	date, ok := mt940Date(m[2])
+	if !ok {
+		return 0, "", "", false
+	}
+	amount, err := money.Parse(strings.ReplaceAll(m[4], ",", "."))
+	if err != nil {
+		return 0, "", "", false
+	}
+	if m[1] == "D" {
+		amount = -amount
+	}
+	return amount, date, m[3], true
+}
+
+// mt940Date converts YYMMDD into YYYY-MM-DD
+func mt940Date(s string) (string, bool) {
+	if len(s) != 6 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(s); err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("20%s-%s-%s", s[:2], s[2:4], s[4:6]), true
+}
+
+// mt940EntryDate completes an MMDD booking date with the year of the value
+// date, adjusting when booking and value date straddle a year boundary
+func mt940EntryDate(valueDate, mmdd string) string {
+	year, _ := strconv.Atoi(valueDate[:4])
+	valueMonth := valueDate[5:7]
+	entryMonth := mmdd[:2]
+	if valueMonth == "01" && entryMonth == "12" {
+		year--
+	} else if valueMonth == "12" && entryMonth == "01" {
+		year++
+	}
+	return fmt.Sprintf("%04d-%s-%s", year, entryMonth, mmdd[2:])
+}
+
+// parseMT940Info extracts the counterparty name and remittance text from a
+// :86: field. It understands the German ?NN subfield layout and SWIFT
+// /NAME/ or /ORDP//NAME/ style codes, and falls back to the raw text.
+func parseMT940Info(info string) (string, string) {
+	info = strings.ReplaceAll(info, "\n", "")
+	if info == "" {
+		return "", ""
+	}
+
+	if strings.Contains(info, "?") {
+		sub := make(map[string]string)
+		parts := strings.Split(info, "?")
+		for _, part := range parts[1:] {
+			if len(part) >= 2 {
+				sub[part[:2]] += part[2:]
+			}
+		}
+		var remittance []string
+		for i := 20; i <= 29; i++ {
+			if v := strings.TrimSpace(sub[strconv.Itoa(i)]); v != "" {
+				remittance = append(remittance, v)
+			}
+		}
+		for i := 60; i <= 63; i++ {
+			if v := strings.TrimSpace(sub[strconv.Itoa(i)]); v != "" {
+				remittance = append(remittance, v)
+			}
+		}
+		name := strings.TrimSpace(strings.TrimSpace(sub["32"]) + " " + strings.TrimSpace(sub["33"]))
+		return name, strings.Join(remittance, " ")
+	}
+
+	if idx := strings.Index(info, "/NAME/"); idx >= 0 {
+		name := info[idx+len("/NAME/"):]
+		if end := strings.Index(name, "/"); end >= 0 {
+			name = name[:end]
+		}
+		remittance := ""
+		if r := strings.Index(info, "/REMI/"); r >= 0 {
+			remittance = info[r+len("/REMI/"):]
+			if end := strings.Index(remittance, "/"); end >= 0 {
+				remittance = remittance[:end]
+			}
+		}
+		return strings.TrimSpace(name), strings.TrimSpace(remittance)
+	}
+
+	return "", strings.TrimSpace(info)
+}
+
+func (p *MT940Parser) Statements() []models.Statement {
+	return p.statements
+}
+
+func (p *MT940Parser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "text" {
+		return s.result()
+	}
+	if regexp.MustCompile(`(?m)^:25:`).MatchString(sample.Text) {
+		s.add(0.3, "MT940 account field :25:")
+	}
+	if regexp.MustCompile(`(?m)^:60[FM]:[CD]\d{6}[A-Z]{3}`).MatchString(sample.Text) {
+		s.add(0.4, "MT940 opening balance :60F:")
+	}
+	if regexp.MustCompile(`(?m)^:61:\d{6}`).MatchString(sample.Text) {
+		s.add(0.3, "MT940 statement lines :61:")
+	}
+	return s.result()
+}
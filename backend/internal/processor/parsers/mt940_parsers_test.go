@@ -0,0 +1,108 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+const mt940Statement = `{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:
+:20:STMT2312
+:25:10020030/1234567
+:28C:00001/001
+:60F:C231228EUR1000,00
+:61:2312290102D12,50NTRFNONREF//B-1
+:86:166?00SEPA-UEBERWEISUNG?20Miete Januar?21Wohnung 3?32Hausverwaltung?33 Meyer
+:61:2312291229C500,00NTRFREF-2
+:86:/ORDP//NAME/Acme GmbH/REMI/Gehalt Dezember/
+:61:231230RC20,00NCHGREF-3
+:86:Storno Gutschrift
+:61:231230RD7,50NMSCREF-4
+:86:Storno Lastschrift
+:62F:C231230EUR1475,00
+-}
+`
+
+func TestMT940Parser(t *testing.T) {
+	p := &MT940Parser{}
+	txs, err := p.Normalize(writeFixture(t, "statement.sta", mt940Statement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 4 {
+		t.Fatalf("got %d transactions, want 4: %+v", len(txs), txs)
+	}
+
+	tests := []struct {
+		date, valueDate string
+		amount          money.Amount
+		description     string
+		merchant        string
+	}{
+		// Booked on 2 January for value 29 December
+		{"2024-01-02", "2023-12-29", -1250, "Hausverwaltung Meyer Miete Januar Wohnung 3", "Hausverwaltung Meyer"},
+		{"2023-12-29", "2023-12-29", 50000, "Acme GmbH Gehalt Dezember", "Acme GmbH"},
+		// RC reverses a credit and takes money out; RD reverses a debit
+		{"2023-12-30", "2023-12-30", -2000, "Storno Gutschrift", ""},
+		{"2023-12-30", "2023-12-30", 750, "Storno Lastschrift", ""},
+	}
+	for i, tt := range tests {
+		tx := txs[i]
+		if tx.Date != tt.date || tx.ValueDate != tt.valueDate || tx.Amount != tt.amount || tx.Description != tt.description {
+			t.Errorf("row %d = %s/%s %s %q, want %s/%s %s %q", i, tx.Date, tx.ValueDate, tx.Amount, tx.Description, tt.date, tt.valueDate, tt.amount, tt.description)
+		}
+		merchant := ""
+		if tx.RawMerchant != nil {
+			merchant = *tx.RawMerchant
+		}
+		if merchant != tt.merchant {
+			t.Errorf("row %d merchant = %q, want %q", i, merchant, tt.merchant)
+		}
+		if tx.Account != "10020030/1234567" || tx.Currency != "EUR" {
+			t.Errorf("row %d account, currency = %q, %q", i, tx.Account, tx.Currency)
+		}
+	}
+	if !txs[0].IsTransfer || !txs[2].IsFee {
+		t.Errorf("transaction type codes not applied: transfer %v, fee %v", txs[0].IsTransfer, txs[2].IsFee)
+	}
+
+	st := p.Statements()
+	if len(st) != 1 {
+		t.Fatalf("got %d statements, want 1", len(st))
+	}
+	assertBalances(t, st[0], 100000, "2023-12-28", 147500, "2023-12-30")
+}
+
+func TestMT940EntryDate(t *testing.T) {
+	tests := []struct {
+		valueDate, mmdd, want string
+	}{
+		{"2023-12-29", "0102", "2024-01-02"},
+		{"2024-01-02", "1229", "2023-12-29"},
+		{"2024-03-15", "0314", "2024-03-14"},
+		{"2024-12-01", "1203", "2024-12-03"},
+	}
+	for _, tt := range tests {
+		if got := mt940EntryDate(tt.valueDate, tt.mmdd); got != tt.want {
+			t.Errorf("mt940EntryDate(%q, %q) = %q, want %q", tt.valueDate, tt.mmdd, got, tt.want)
+		}
+	}
+}
+
+func TestParseMT940Info(t *testing.T) {
+	tests := []struct {
+		info, name, remittance string
+	}{
+		{"166?00GUTSCHRIFT?20Rechnung 42?21\n vom Maerz?32Muster?33mann KG", "Muster mann KG", "Rechnung 42 vom Maerz"},
+		{"/NAME/Acme GmbH/REMI/Gehalt/", "Acme GmbH", "Gehalt"},
+		{"/ORDP//NAME/Jane Doe/ADDR/Main St", "Jane Doe", ""},
+		{"Barauszahlung Automat", "", "Barauszahlung Automat"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		name, remittance := parseMT940Info(tt.info)
+		if name != tt.name || remittance != tt.remittance {
+			t.Errorf("parseMT940Info(%q) = %q, %q, want %q, %q", tt.info, name, remittance, tt.name, tt.remittance)
+		}
+	}
+}
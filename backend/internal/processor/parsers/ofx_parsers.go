@@ -0,0 +1,243 @@
+package parsers
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+// OFXParser reads OFX 1.x (SGML) and 2.x (XML) exports, including Quicken QFX files
+type OFXParser struct {
+	statements []models.Statement
+}
+
+func (p *OFXParser) Normalize(filePath string) ([]models.Transaction, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	root := parseOFX(string(raw))
+	if root.find("OFX") == nil {
+		return nil, fmt.Errorf("could not find OFX body")
+	}
+
+	source := "ofx"
+	if org := root.value("SONRS", "FI", "ORG"); org != "" {
+		source = strings.ToLower(org)
+	}
+
+	p.statements = nil
+	var transactions []models.Transaction
+
+	// Bank and credit card statements share the same transaction layout
+	bankStmts := root.findAll("STMTRS")
+	stmts := append(bankStmts, root.findAll("CCSTMTRS")...)
+	for i, stmt := range stmts {
+		cardStatement := i >= len(bankStmts)
+		currency := stmt.value("CURDEF")
+		account := stmt.value("BANKACCTFROM", "ACCTID")
+		if account == "" {
+			account = stmt.value("CCACCTFROM", "ACCTID")
+		}
+
+		statement := models.Statement{Source: source, Account: account, Currency: currency}
+		if bal := stmt.find("LEDGERBAL"); bal != nil {
+			if amount, err := parseOFXAmount(bal.value("BALAMT")); err == nil {
+				statement.ClosingBalance = &amount
+				statement.ClosingDate = parseOFXDate(bal.value("DTASOF"))
+			}
+		}
+		p.statements = append(p.statements, statement)
+
+		for _, trn := range stmt.findAll("STMTTRN") {
+			dateISO := parseOFXDate(trn.value("DTPOSTED"))
+			amount, err := parseOFXAmount(trn.value("TRNAMT"))
+			if dateISO == "" || err != nil {
+				continue
+			}
+
+			trnType := strings.ToUpper(trn.value("TRNTYPE"))
+			name := trn.value("NAME")
+			if name == "" {
+				name = trn.value("PAYEE", "NAME")
+			}
+			memo := trn.value("MEMO")
+			description := name
+			if memo != "" && !strings.EqualFold(memo, name) {
+				description = strings.TrimSpace(name + " " + memo)
+			}
+
+			txCurrency := currency
+			if cur := trn.value("CURRENCY", "CURSYM"); cur != "" {
+				txCurrency = cur
+			}
+
+			direction := "debit"
+			if amount > 0 {
+				direction = "credit"
+			}
+
+			isTax := containsAny(description, "iva", "percepción", "impuesto", "tax")
+			isTransfer := trnType == "XFER" || containsAny(description, "transferencia", "transfer")
+			isFee := trnType == "SRVCHG" || trnType == "FEE" || containsAny(description, "comisión", "fee")
+
+			var merchantPtr *string
+			if name != "" && !isTransfer {
+				merchant := name
+				merchantPtr = &merchant
+			}
+
+			// FITID is unique per account, so it keeps IDs stable across re-downloads
+			fitID := trn.value("FITID")
+			if fitID == "" {
+				fitID = description
+			}
+
+			tx := models.Transaction{
+				ID:          common.GenerateID(source, account, dateISO, amount.String(), "fitid:"+fitID),
+				Source:      source,
+				Account:     account,
+				Date:        dateISO,
+				Amount:      amount,
+				Currency:    txCurrency,
+				Description: description,
+				Direction:   direction,
+				Merchant:    merchantPtr,
+				IsTransfer:  isTransfer,
+				IsFee:       isFee,
+				IsTax:       isTax,
+			}
+			if cardStatement {
+				classifyCardStatement(&tx)
+			} else {
+				classify(&tx)
+			}
+			transactions = append(transactions, tx)
+		}
+	}
+
+	return transactions, nil
+}
+
+func (p *OFXParser) Statements() []models.Statement {
+	return p.statements
+}
+
+func (p *OFXParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "text" {
+		return s.result()
+	}
+	upper := strings.ToUpper(sample.Text)
+	if strings.Contains(upper, "OFXHEADER") || strings.Contains(upper, "<OFX>") {
+		s.add(0.7, "OFX header or root element")
+	}
+	if strings.Contains(upper, "<STMTTRN>") {
+		s.add(0.3, "contains STMTTRN entries")
+	}
+	return s.result()
+}
+
+// ofxNode is an element of an OFX document. Leaf elements carry a value.
+type ofxNode struct {
+	name     string
+	text     string
+	children []*ofxNode
+}
+
+var ofxTagRegex = regexp.MustCompile(`<(/?)([A-Za-z0-9_.]+)([^>]*)>([^<]*)`)
+
+// parseOFX builds an element tree from SGML or XML OFX. In SGML leaf
+// elements are not closed, so any tag followed by text is treated as a leaf
+// and closing tags pop the stack up to the matching aggregate.
+func parseOFX(content string) *ofxNode {
+	root := &ofxNode{}
+	stack := []*ofxNode{root}
+
+	for _, m := range ofxTagRegex.FindAllStringSubmatch(content, -1) {
+		closing, name, text := m[1] == "/", strings.ToUpper(m[2]), strings.TrimSpace(m[4])
+		selfClosing := strings.HasSuffix(m[3], "/")
+		parent := stack[len(stack)-1]
+
+		if closing {
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		node := &ofxNode{name: name}
+		parent.children = append(parent.children, node)
+		if text != "" {
+			// Both forms escape markup characters, e.g. AT&amp;T
+			node.text = html.UnescapeString(text)
+		} else if !selfClosing {
+			stack = append(stack, node)
+		}
+	}
+	return root
+}
+
+// find returns the first descendant with the given name
+func (n *ofxNode) find(name string) *ofxNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+		if found := c.find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll returns every descendant with the given name
+func (n *ofxNode) findAll(name string) []*ofxNode {
+	var found []*ofxNode
+	for _, c := range n.children {
+		if c.name == name {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, c.findAll(name)...)
+	}
+	return found
+}
+
+// value follows a path of descendant names and returns the leaf value
+func (n *ofxNode) value(path ...string) string {
+	cur := n
+	for _, name := range path {
+		if cur = cur.find(name); cur == nil {
+			return ""
+		}
+	}
+	return cur.text
+}
+
+// parseOFXDate converts YYYYMMDD[HHMMSS[.XXX]][TZ] into YYYY-MM-DD
+func parseOFXDate(s string) string {
+	if len(s) < 8 {
+		return ""
+	}
+	if _, err := strconv.Atoi(s[:8]); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%s", s[:4], s[4:6], s[6:8])
+}
+
+// parseOFXAmount parses an OFX amount, where a comma may be the decimal separator
+func parseOFXAmount(s string) (money.Amount, error) {
+	return money.Parse(strings.ReplaceAll(s, ",", "."))
+}
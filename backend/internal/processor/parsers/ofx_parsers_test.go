@@ -0,0 +1,210 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+// writeFixture writes content to a file named name in a temporary directory
+func writeFixture(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS>
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<FI><ORG>Galicia<FID>1234</FI>
+</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>ARS
+<BANKACCTFROM><BANKID>007<ACCTID>4002-1<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20240501<DTEND>20240531
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240503120000[-3:ART]
+<TRNAMT>-1500,50
+<FITID>A-1
+<NAME>AT&amp;T WIRELESS
+<MEMO>Factura mayo
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240510
+<TRNAMT>250000.00
+<FITID>A-2
+<NAME>HABERES ACME
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>98765.43<DTASOF>20240531</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+func TestOFXParserSGML(t *testing.T) {
+	p := &OFXParser{}
+	txs, err := p.Normalize(writeFixture(t, "galicia.ofx", ofxSGML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2: %+v", len(txs), txs)
+	}
+
+	bill := txs[0]
+	if bill.Source != "galicia" || bill.Account != "4002-1" || bill.Currency != "ARS" {
+		t.Errorf("source, account, currency = %q, %q, %q", bill.Source, bill.Account, bill.Currency)
+	}
+	if bill.Date != "2024-05-03" || bill.Amount != -150050 || bill.Direction != "debit" {
+		t.Errorf("bill = %s %s %s, want 2024-05-03 -1500.50 debit", bill.Date, bill.Amount, bill.Direction)
+	}
+	if bill.Description != "AT&T WIRELESS Factura mayo" {
+		t.Errorf("description = %q, want entities decoded", bill.Description)
+	}
+	if bill.RawMerchant == nil || *bill.RawMerchant != "AT&T WIRELESS" {
+		t.Errorf("raw merchant = %v, want AT&T WIRELESS", bill.RawMerchant)
+	}
+	if txs[1].Amount != 25000000 || txs[1].Direction != "credit" || txs[1].Description != "HABERES ACME" {
+		t.Errorf("salary = %s %s %q", txs[1].Amount, txs[1].Direction, txs[1].Description)
+	}
+
+	statements := p.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+	st := statements[0]
+	if st.ClosingBalance == nil || *st.ClosingBalance != 9876543 || st.ClosingDate != "2024-05-31" {
+		t.Errorf("closing balance = %v on %q, want 98765.43 on 2024-05-31", st.ClosingBalance, st.ClosingDate)
+	}
+}
+
+// IDs come from FITID, so a re-download with a reworded memo keeps them
+func TestOFXParserFITID(t *testing.T) {
+	p := &OFXParser{}
+	txs, err := p.Normalize(writeFixture(t, "galicia.ofx", ofxSGML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := common.GenerateID("galicia", "4002-1", "2024-05-03", "-1500.50", "fitid:A-1")
+	if txs[0].ID != want {
+		t.Errorf("ID = %s, want the FITID based %s", txs[0].ID, want)
+	}
+
+	reworded := strings.Replace(ofxSGML, "Factura mayo", "Factura 05/2024", 1)
+	again, err := p.Normalize(writeFixture(t, "galicia.ofx", reworded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].ID != txs[0].ID {
+		t.Errorf("ID changed with the memo: %s, %s", txs[0].ID, again[0].ID)
+	}
+	if txs[0].ID == txs[1].ID {
+		t.Errorf("rows share ID %s", txs[0].ID)
+	}
+}
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE"?>
+<OFX>
+  <SIGNONMSGSRSV1><SONRS><FI><ORG>Brubank</ORG></FI></SONRS></SIGNONMSGSRSV1>
+  <BANKMSGSRSV1><STMTTRNRS><STMTRS>
+    <CURDEF>USD</CURDEF>
+    <BANKACCTFROM><BANKID>143</BANKID><ACCTID>99-7</ACCTID><ACCTTYPE>SAVINGS</ACCTTYPE></BANKACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>XFER</TRNTYPE>
+        <DTPOSTED>20240612</DTPOSTED>
+        <TRNAMT>-100.00</TRNAMT>
+        <FITID>X-9</FITID>
+        <PAYEE><NAME>Juan &lt;ahorro&gt;</NAME></PAYEE>
+      </STMTTRN>
+    </BANKTRANLIST>
+    <LEDGERBAL><BALAMT>1200.00</BALAMT><DTASOF>20240630</DTASOF></LEDGERBAL>
+  </STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+func TestOFXParserXML(t *testing.T) {
+	p := &OFXParser{}
+	txs, err := p.Normalize(writeFixture(t, "brubank.qfx", ofxXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("got %d transactions, want 1: %+v", len(txs), txs)
+	}
+	tx := txs[0]
+	if tx.Source != "brubank" || tx.Account != "99-7" || tx.Currency != "USD" || tx.Date != "2024-06-12" || tx.Amount != -10000 {
+		t.Errorf("transaction = %+v", tx)
+	}
+	if tx.Description != "Juan <ahorro>" {
+		t.Errorf("description = %q, want the PAYEE name decoded", tx.Description)
+	}
+	if !tx.IsTransfer || tx.Merchant != nil {
+		t.Errorf("XFER row: transfer = %v, merchant = %v, want a transfer without merchant", tx.IsTransfer, tx.Merchant)
+	}
+	st := p.Statements()
+	if len(st) != 1 || st[0].ClosingBalance == nil || *st[0].ClosingBalance != 120000 || st[0].ClosingDate != "2024-06-30" {
+		t.Errorf("statements = %+v", st)
+	}
+}
+
+const ofxCard = `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+<CURDEF>ARS
+<CCACCTFROM><ACCTID>4509XXXX1234</CCACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240705<TRNAMT>150000.00<FITID>C-1<NAME>SU PAGO EN PESOS</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240706<TRNAMT>-150000.00<FITID>C-2<NAME>PAGO TARJETA VISA</STMTTRN>
+</BANKTRANLIST>
+</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+// Card statements flag the payment credit, not debits that look like a bank
+// side card payment
+func TestOFXParserCardStatement(t *testing.T) {
+	p := &OFXParser{}
+	txs, err := p.Normalize(writeFixture(t, "visa.ofx", ofxCard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2: %+v", len(txs), txs)
+	}
+	if txs[0].Account != "4509XXXX1234" || txs[0].Source != "ofx" {
+		t.Errorf("source, account = %q, %q", txs[0].Source, txs[0].Account)
+	}
+	if !txs[0].IsCardPayment {
+		t.Errorf("%q not flagged as the card payment", txs[0].Description)
+	}
+	if txs[1].IsCardPayment {
+		t.Errorf("card debit %q flagged as a card payment", txs[1].Description)
+	}
+	if st := p.Statements(); len(st) != 1 || st[0].ClosingBalance != nil {
+		t.Errorf("statements = %+v, want one without a closing balance", st)
+	}
+}
+
+func TestOFXParserNoBody(t *testing.T) {
+	p := &OFXParser{}
+	if _, err := p.Normalize(writeFixture(t, "empty.ofx", "OFXHEADER:100\n")); err == nil {
+		t.Error("want an error for a file without an OFX element")
+	}
+}
@@ -59,8 +59,6 @@ func (p *BrubankPDFParser) Normalize(filePath string) ([]models.Transaction, err
 			isTransfer := containsAny(description, "cuenta tuya", "transferencia", "enviada", "recibida")
 			isFee := containsAny(description, "comisión", "reimpresión", "intereses pagados", "mantenimiento")
 
-			cat, sub := common.InferCategory(description)
-
 			merchant := ""
 			if !isTax && !isTransfer && !isFee {
 				parts := strings.Split(description, " ")
@@ -74,8 +72,8 @@ func (p *BrubankPDFParser) Normalize(filePath string) ([]models.Transaction, err
 				merchantPtr = &merchant
 			}
 
-			transactions = append(transactions, models.Transaction{
-				ID:          common.GenerateID("brubank", "caja_ahorro_pesos", dateISO, fmt.Sprintf("%.2f", amount), description),
+			tx := models.Transaction{
+				ID:          common.GenerateID("brubank", "caja_ahorro_pesos", dateISO, amount.String(), description),
 				Source:      "brubank",
 				Account:     "caja_ahorro_pesos",
 				Date:        dateISO,
@@ -84,13 +82,13 @@ func (p *BrubankPDFParser) Normalize(filePath string) ([]models.Transaction, err
 				Description: description,
 				Direction:   direction,
 				Merchant:    merchantPtr,
-				Category:    cat,
-				Subcategory: sub,
 				Balance:     &balance,
 				IsTransfer:  isTransfer,
 				IsFee:       isFee,
 				IsTax:       isTax,
-			})
+			}
+			classify(&tx)
+			transactions = append(transactions, tx)
 
 			i += 5
 		}
@@ -99,6 +97,21 @@ func (p *BrubankPDFParser) Normalize(filePath string) ([]models.Transaction, err
 	return transactions, nil
 }
 
+func (p *BrubankPDFParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "pdf" {
+		return s.result()
+	}
+	if strings.Contains(strings.ToLower(sample.Text), "brubank") {
+		s.add(0.6, "PDF mentions Brubank")
+	}
+	dateRegex := regexp.MustCompile(`(?m)^\s*\d{2}-\d{2}-\d{2}\s*$`)
+	if dateRegex.MatchString(sample.Text) {
+		s.add(0.3, "movement dates on their own line (DD-MM-YY)")
+	}
+	return s.result()
+}
+
 type SantanderVisaPDFParser struct{}
 
 func (p *SantanderVisaPDFParser) Normalize(filePath string) ([]models.Transaction, error) {
@@ -152,7 +165,7 @@ func (p *SantanderVisaPDFParser) Normalize(filePath string) ([]models.Transactio
 		amount := common.CleanAmount(amountStr)
 		direction := "debit"
 		if isNegative {
-			amount = float64(int(amount*100)) / 100 // positive on CC usually means credit/payment
+			// positive on CC usually means credit/payment
 			direction = "credit"
 		} else {
 			amount = -amount
@@ -164,20 +177,16 @@ func (p *SantanderVisaPDFParser) Normalize(filePath string) ([]models.Transactio
 		}
 
 		isTax := containsAny(description, "impuesto", "iva", "percepción", "db.rg")
-		descUpper := strings.ToUpper(description)
-		isTransfer := strings.Contains(descUpper, "SU PAGO") || strings.Contains(descUpper, "PAGO EN")
 		isFee := containsAny(description, "comision", "cargo", "interes")
 
-		cat, sub := common.InferCategory(description)
-
 		merchant := strings.Split(description, " ")[0]
 		var merchantPtr *string
 		if merchant != "" {
 			merchantPtr = &merchant
 		}
 
-		transactions = append(transactions, models.Transaction{
-			ID:          common.GenerateID("santander", "credito_visa", dateISO, fmt.Sprintf("%.2f", amount), description),
+		tx := models.Transaction{
+			ID:          common.GenerateID("santander", "credito_visa", dateISO, amount.String(), description),
 			Source:      "santander",
 			Account:     "credito_visa",
 			Date:        dateISO,
@@ -186,17 +195,35 @@ func (p *SantanderVisaPDFParser) Normalize(filePath string) ([]models.Transactio
 			Description: description,
 			Direction:   direction,
 			Merchant:    merchantPtr,
-			Category:    cat,
-			Subcategory: sub,
-			IsTransfer:  isTransfer,
 			IsFee:       isFee,
 			IsTax:       isTax,
-		})
+		}
+		// Payments settle the statement; they are linked to the bank debit
+		// instead of being treated as transfers
+		classifyCardStatement(&tx)
+		transactions = append(transactions, tx)
 	}
 
 	return transactions, nil
 }
 
+func (p *SantanderVisaPDFParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "pdf" {
+		return s.result()
+	}
+	if regexp.MustCompile(`CIERRE\s+\d{2}\s+\w{3}\s+\d{2}`).MatchString(sample.Text) {
+		s.add(0.4, "card statement closing date (CIERRE)")
+	}
+	if strings.Contains(strings.ToUpper(sample.Text), "VISA") {
+		s.add(0.3, "PDF mentions VISA")
+	}
+	if strings.Contains(strings.ToLower(sample.Text), "santander") {
+		s.add(0.2, "PDF mentions Santander")
+	}
+	return s.result()
+}
+
 func readPDFText(path string) (string, error) {
 	f, r, err := pdf.Open(path)
 	if err != nil {
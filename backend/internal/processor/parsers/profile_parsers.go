@@ -0,0 +1,238 @@
+package parsers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+)
+
+// ProfileCSVParser reads any CSV export described by a saved import profile
+type ProfileCSVParser struct {
+	Profile models.ImportProfile
+}
+
+// ValidateProfile checks that an import profile has everything the parser needs
+func ValidateProfile(p models.ImportProfile) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len([]rune(p.Separator)) > 1 {
+		return errors.New("separator must be a single character")
+	}
+	if p.DateColumn == "" || p.DateLayout == "" {
+		return errors.New("date_column and date_layout are required")
+	}
+	if p.DescriptionColumn == "" {
+		return errors.New("description_column is required")
+	}
+	if p.AmountColumn == "" && p.DebitColumn == "" && p.CreditColumn == "" {
+		return errors.New("amount_column or debit_column/credit_column is required")
+	}
+	if p.DecimalStyle != "" && p.DecimalStyle != "comma" && p.DecimalStyle != "dot" {
+		return errors.New("decimal_style must be comma or dot")
+	}
+	if p.Source == "" || p.Account == "" {
+		return errors.New("source and account are required")
+	}
+	return nil
+}
+
+func (p *ProfileCSVParser) Normalize(filePath string) ([]models.Transaction, error) {
+	prof := p.Profile
+	if err := ValidateProfile(prof); err != nil {
+		return nil, fmt.Errorf("invalid import profile: %w", err)
+	}
+
+	rawLines, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimPrefix(string(rawLines), "\ufeff"), "\n")
+	headerIdx := 0
+	marker := prof.HeaderMarker
+	if marker == "" {
+		marker = prof.DateColumn
+	}
+	for i, line := range lines {
+		if strings.Contains(line, marker) {
+			headerIdx = i
+			break
+		}
+	}
+
+	sep := prof.Separator
+	if sep == "" {
+		sep = ","
+		if strings.Count(lines[headerIdx], ";") > strings.Count(lines[headerIdx], ",") {
+			sep = ";"
+		}
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.Join(lines[headerIdx:], "\n")))
+	reader.Comma = []rune(sep)[0]
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) < 2 {
+		return nil, nil
+	}
+
+	colMap := make(map[string]int)
+	for i, h := range records[0] {
+		colMap[strings.TrimSpace(h)] = i
+	}
+	for _, col := range []string{prof.DateColumn, prof.DescriptionColumn, prof.AmountColumn, prof.DebitColumn, prof.CreditColumn, prof.CurrencyColumn} {
+		if _, ok := colMap[col]; col != "" && !ok {
+			return nil, fmt.Errorf("column %q not found in header", col)
+		}
+	}
+
+	cell := func(row []string, col string) string {
+		idx, ok := colMap[col]
+		if col == "" || !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+
+	layout := goDateLayout(prof.DateLayout)
+	var transactions []models.Transaction
+	for n, row := range records[1:] {
+		// Rows without a date are titles, totals or footers
+		t, err := time.Parse(layout, cell(row, prof.DateColumn))
+		if err != nil {
+			continue
+		}
+		dateISO := t.Format("2006-01-02")
+
+		var amount money.Amount
+		if prof.AmountColumn != "" {
+			amount, err = profileAmount(cell(row, prof.AmountColumn), prof.DecimalStyle)
+		} else {
+			var debit, credit money.Amount
+			debit, err = profileAmount(cell(row, prof.DebitColumn), prof.DecimalStyle)
+			if err == nil {
+				credit, err = profileAmount(cell(row, prof.CreditColumn), prof.DecimalStyle)
+			}
+			if debit < 0 {
+				debit = -debit
+			}
+			amount = credit - debit
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", headerIdx+n+2, err)
+		}
+		if amount == 0 {
+			continue
+		}
+
+		description := cell(row, prof.DescriptionColumn)
+		currency := cell(row, prof.CurrencyColumn)
+		if currency == "" {
+			currency = prof.Currency
+		}
+		if currency == "" {
+			currency = "ARS"
+		}
+
+		direction := "debit"
+		if amount > 0 {
+			direction = "credit"
+		}
+
+		isTax := containsAny(description, "iva", "percepción", "impuesto", "ganancias", "sircreb", "tax")
+		isTransfer := containsAny(description, "transferencia", "transfer", "cuenta tuya")
+		isFee := containsAny(description, "comisión", "comision", "mantenimiento", "fee")
+
+		tx := models.Transaction{
+			ID:          common.GenerateID(prof.Source, prof.Account, dateISO, amount.String(), description),
+			Source:      prof.Source,
+			Account:     prof.Account,
+			Date:        dateISO,
+			Amount:      amount,
+			Currency:    currency,
+			Description: description,
+			Direction:   direction,
+			IsTransfer:  isTransfer,
+			IsFee:       isFee,
+			IsTax:       isTax,
+		}
+		classify(&tx)
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+func (p *ProfileCSVParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "text" {
+		return s.result()
+	}
+	marker := p.Profile.HeaderMarker
+	if marker == "" {
+		marker = p.Profile.DateColumn
+	}
+	if marker != "" && headerLine(sample, marker) != "" {
+		s.add(0.8, fmt.Sprintf("CSV header matches import profile %q", p.Profile.Name))
+	}
+	return s.result()
+}
+
+var (
+	dateTokenReplacer = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "hh", "15", "mm", "04", "ss", "05")
+	dateTokenRegex    = regexp.MustCompile(`YY|MM|DD`)
+)
+
+// goDateLayout accepts DD/MM/YYYY style layouts as well as Go reference
+// layouts such as 02/01/2006, which have none of the YY, MM or DD tokens
+func goDateLayout(layout string) string {
+	if !dateTokenRegex.MatchString(layout) {
+		return layout
+	}
+	return dateTokenReplacer.Replace(layout)
+}
+
+var nonNumericRegex = regexp.MustCompile(`[^\d,\.\-]`)
+
+// profileAmount parses an amount using the decimal style of the profile.
+// Trailing minus signs and parentheses are read as negative amounts, and an
+// empty cell or a lone dash, as in "$ -", is zero.
+func profileAmount(s, decimalStyle string) (money.Amount, error) {
+	if strings.Trim(s, " $-") == "" {
+		return 0, nil
+	}
+	negative := strings.HasSuffix(s, "-") || (strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"))
+	clean := nonNumericRegex.ReplaceAllString(s, "")
+	clean = strings.TrimSuffix(clean, "-")
+
+	if decimalStyle == "dot" {
+		clean = strings.ReplaceAll(clean, ",", "")
+	} else {
+		clean = strings.ReplaceAll(clean, ".", "")
+		clean = strings.ReplaceAll(clean, ",", ".")
+	}
+
+	val, err := money.Parse(clean)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative && val > 0 {
+		val = -val
+	}
+	return val, nil
+}
@@ -0,0 +1,144 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+)
+
+func profile() models.ImportProfile {
+	return models.ImportProfile{
+		Name:              "Supervielle",
+		HeaderMarker:      "Fecha",
+		DateColumn:        "Fecha",
+		DateLayout:        "DD/MM/YYYY",
+		AmountColumn:      "Importe",
+		DecimalStyle:      "comma",
+		DescriptionColumn: "Concepto",
+		Source:            "supervielle",
+		Account:           "caja_ahorro",
+	}
+}
+
+// The header is found below the bank's preamble and the separator is guessed from it
+func TestProfileCSVParserHeaderAndSeparator(t *testing.T) {
+	content := "Banco Supervielle, Movimientos\n" +
+		"Cuenta: 123-456, Caja de ahorro\n" +
+		"\n" +
+		"Fecha;Concepto;Importe;Saldo\n" +
+		"02/05/2024;COMPRA SUPERMERCADO DIA;-12.345,67;100.000,00\n" +
+		"03/05/2024;DEVOLUCION;500,00;100.500,00\n" +
+		"04/05/2024;SIN MOVIMIENTO;0,00;100.500,00\n" +
+		"Total;;-11.845,67;\n"
+	p := &ProfileCSVParser{Profile: profile()}
+	txs, err := p.Normalize(writeFixture(t, "supervielle.csv", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2: %+v", len(txs), txs)
+	}
+	if txs[0].Date != "2024-05-02" || txs[0].Amount != -1234567 || txs[0].Description != "COMPRA SUPERMERCADO DIA" || txs[0].Direction != "debit" {
+		t.Errorf("first row = %s %s %q %s", txs[0].Date, txs[0].Amount, txs[0].Description, txs[0].Direction)
+	}
+	if txs[1].Amount != 50000 || txs[1].Direction != "credit" || txs[1].Currency != "ARS" || txs[1].Source != "supervielle" || txs[1].Account != "caja_ahorro" {
+		t.Errorf("second row = %+v", txs[1])
+	}
+}
+
+func TestProfileCSVParserDebitCredit(t *testing.T) {
+	prof := profile()
+	prof.HeaderMarker = ""
+	prof.Separator = ","
+	prof.DateLayout = "2006-01-02"
+	prof.AmountColumn = ""
+	prof.DebitColumn = "Debito"
+	prof.CreditColumn = "Credito"
+	prof.DecimalStyle = "dot"
+	prof.CurrencyColumn = "Moneda"
+	content := "Fecha,Concepto,Debito,Credito,Moneda\n" +
+		"2024-05-02,PAGO ALQUILER,\"1,200.50\",,ARS\n" +
+		"2024-05-03,HONORARIOS,,\"2,000.00\",USD\n" +
+		// Some banks write debits with a sign
+		"2024-05-04,COMISION,-15.00,,ARS\n"
+	txs, err := (&ProfileCSVParser{Profile: prof}).Normalize(writeFixture(t, "ciudad.csv", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		amount   money.Amount
+		currency string
+	}{{-120050, "ARS"}, {200000, "USD"}, {-1500, "ARS"}}
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(want), txs)
+	}
+	for i, w := range want {
+		if txs[i].Amount != w.amount || txs[i].Currency != w.currency {
+			t.Errorf("row %d = %s %s, want %s %s", i, txs[i].Amount, txs[i].Currency, w.amount, w.currency)
+		}
+	}
+	if !txs[2].IsFee {
+		t.Error("COMISION not flagged as a fee")
+	}
+}
+
+func TestProfileCSVParserInvalidAmount(t *testing.T) {
+	content := "Fecha;Concepto;Importe\n" +
+		"02/05/2024;CAFE;-1.500,00\n" +
+		"03/05/2024;ALMUERZO;N/D\n"
+	_, err := (&ProfileCSVParser{Profile: profile()}).Normalize(writeFixture(t, "bad.csv", content))
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "N/D") {
+		t.Errorf("err = %v, want the invalid amount reported with its line", err)
+	}
+}
+
+func TestProfileAmount(t *testing.T) {
+	tests := []struct {
+		s, style string
+		want     money.Amount
+	}{
+		{"1.234,56", "comma", 123456},
+		{"1.234,56", "", 123456},
+		{"-1.234,56", "comma", -123456},
+		{"1.234,56-", "comma", -123456},
+		{"(1.234,56)", "comma", -123456},
+		{"$ 1,234.56", "dot", 123456},
+		{"1,234.56-", "dot", -123456},
+		{"(1,234.56)", "dot", -123456},
+		{"", "comma", 0},
+		{"-", "comma", 0},
+		{"$ -", "dot", 0},
+	}
+	for _, tt := range tests {
+		got, err := profileAmount(tt.s, tt.style)
+		if err != nil || got != tt.want {
+			t.Errorf("profileAmount(%q, %q) = %s, %v, want %s", tt.s, tt.style, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"N/D", "abc", "1.2.3"} {
+		if got, err := profileAmount(s, "dot"); err == nil {
+			t.Errorf("profileAmount(%q) = %s, want an error", s, got)
+		}
+	}
+}
+
+func TestGoDateLayout(t *testing.T) {
+	tests := []struct {
+		layout, want string
+	}{
+		{"DD/MM/YYYY", "02/01/2006"},
+		{"DD-MM-YY", "02-01-06"},
+		{"YYYY-MM-DD hh:mm:ss", "2006-01-02 15:04:05"},
+		{"02/01/2006", "02/01/2006"},
+		{"2006-01-02", "2006-01-02"},
+		{"01/02/06", "01/02/06"},
+		{"Jan 2, 2006", "Jan 2, 2006"},
+	}
+	for _, tt := range tests {
+		if got := goDateLayout(tt.layout); got != tt.want {
+			t.Errorf("goDateLayout(%q) = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
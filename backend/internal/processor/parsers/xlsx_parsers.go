@@ -70,10 +70,8 @@ func (p *SantanderXLSXParser) Normalize(filePath string) ([]models.Transaction,
 		isTransfer := strings.Contains(strings.ToLower(description), "transferencia")
 		isFee := containsAny(description, "comision", "cargo", "interes")
 
-		cat, sub := common.InferCategory(description)
-
-		transactions = append(transactions, models.Transaction{
-			ID:          common.GenerateID("santander", "caja_ahorro_pesos", dateISO, fmt.Sprintf("%.2f", amount), description),
+		tx := models.Transaction{
+			ID:          common.GenerateID("santander", "caja_ahorro_pesos", dateISO, amount.String(), description),
 			Source:      "santander",
 			Account:     "caja_ahorro_pesos",
 			Date:        dateISO,
@@ -81,14 +79,41 @@ func (p *SantanderXLSXParser) Normalize(filePath string) ([]models.Transaction,
 			Currency:    "ARS",
 			Description: description,
 			Direction:   direction,
-			Category:    cat,
-			Subcategory: sub,
 			Balance:     &balance,
 			IsTransfer:  isTransfer,
 			IsFee:       isFee,
 			IsTax:       isTax,
-		})
+		}
+		classify(&tx)
+		transactions = append(transactions, tx)
 	}
 
 	return transactions, nil
 }
+
+func (p *SantanderXLSXParser) Detect(sample *common.Sample) common.Detection {
+	var s scorer
+	if sample.Kind != "xlsx" {
+		return s.result()
+	}
+
+	dateRegex := regexp.MustCompile(`\d{2}/\d{2}/\d{4}`)
+	movements := 0
+	for i := 12; i < len(sample.Rows); i++ {
+		row := sample.Rows[i]
+		if len(row) >= 8 && dateRegex.MatchString(row[1]) {
+			movements++
+		}
+	}
+	if movements > 0 {
+		s.add(0.5, fmt.Sprintf("%d movement rows from row 13 with a DD/MM/YYYY date in column B", movements))
+	}
+
+	for i := 0; i < len(sample.Rows) && i < 12; i++ {
+		if strings.Contains(strings.ToLower(strings.Join(sample.Rows[i], " ")), "santander") {
+			s.add(0.4, "sheet header mentions Santander")
+			break
+		}
+	}
+	return s.result()
+}
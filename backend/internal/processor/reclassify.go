@@ -0,0 +1,150 @@
+package processor
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
+)
+
+// ReclassifyFilter narrows a reclassification run. Empty fields match everything;
+// From and To are inclusive YYYY-MM-DD dates.
+type ReclassifyFilter struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Source string `json:"source"`
+}
+
+// CategoryMove counts transactions that moved from one category to another
+type CategoryMove struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// ReclassifyReport summarises a reclassification run
+type ReclassifyReport struct {
+	Examined int            `json:"examined"`
+	Skipped  int            `json:"skipped"` // user-locked or neutralized transfers
+	Changed  int            `json:"changed"`
+	Moves    []CategoryMove `json:"moves"`
+}
+
+// Reclassify re-runs the current rules over a user's stored transactions and
+// saves the new categories. Transactions whose classification was edited by
+// the user or that were neutralized as internal transfers are skipped.
+// Transactions a rule categorized that no rule matches any more, because the
+// rule was deleted or narrowed, lose that category and go to the learned
+// model like an uncategorized import; other transactions no rule matches keep
+// the category they already had.
+func Reclassify(userID uuid.UUID, filter ReclassifyFilter) (ReclassifyReport, error) {
+	rules, err := classifier.ForUser(userID)
+	if err != nil {
+		return ReclassifyReport{}, err
+	}
+
+	report := ReclassifyReport{Moves: []CategoryMove{}}
+	moves := make(map[[2]string]int)
+	var changed []models.Transaction
+
+	// Stale rule categories are cleared before the model is trained so it
+	// does not learn them back
+	txs := db.GetDB().GetTransactions(userID)
+	type result struct {
+		before, after models.Transaction
+		stale         bool
+	}
+	var results []result
+	for i, tx := range txs {
+		if !filter.matches(tx) {
+			continue
+		}
+		report.Examined++
+		if tx.ClassificationLocked() || tx.Neutralized {
+			report.Skipped++
+			continue
+		}
+
+		next := tx
+		if rules.Classify(&next) {
+			next.NeedsReview = false
+			results = append(results, result{before: tx, after: next})
+			continue
+		}
+		if !fromRule(tx) {
+			continue
+		}
+		next.Category, next.Subcategory, next.Provenance = nil, nil, nil
+		next.SuggestedCategory, next.SuggestedSubcategory, next.CategoryConfidence = nil, nil, nil
+		next.NeedsReview = false
+		txs[i] = next
+		results = append(results, result{before: tx, after: next, stale: true})
+	}
+
+	model := classifier.Train(txs)
+	for _, r := range results {
+		next := r.after
+		if r.stale {
+			model.Fallback(&next)
+		}
+		from, to := categoryLabel(r.before), categoryLabel(next)
+		if from == to && r.before.NeedsReview == next.NeedsReview {
+			continue
+		}
+		report.Changed++
+		moves[[2]string{from, to}]++
+		changed = append(changed, next)
+	}
+
+	if len(changed) > 0 {
+		if err := db.GetDB().UpdateTransactionCategories(userID, changed); err != nil {
+			return ReclassifyReport{}, err
+		}
+	}
+
+	for k, n := range moves {
+		report.Moves = append(report.Moves, CategoryMove{From: k[0], To: k[1], Count: n})
+	}
+	sort.Slice(report.Moves, func(i, j int) bool {
+		a, b := report.Moves[i], report.Moves[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return report, nil
+}
+
+func (f ReclassifyFilter) matches(tx models.Transaction) bool {
+	date := dayOf(tx.Date)
+	if f.From != "" && date < f.From {
+		return false
+	}
+	if f.To != "" && date > f.To {
+		return false
+	}
+	return f.Source == "" || f.Source == tx.Source
+}
+
+// fromRule reports whether a global or user rule set the category of tx
+func fromRule(tx models.Transaction) bool {
+	return tx.Category != nil && tx.Provenance != nil &&
+		(tx.Provenance.Stage == classifier.StageRule || tx.Provenance.Stage == classifier.StageUserRule)
+}
+
+// categoryLabel renders category/subcategory, with "" for uncategorized
+func categoryLabel(tx models.Transaction) string {
+	label := ""
+	if tx.Category != nil {
+		label = *tx.Category
+	}
+	if tx.Subcategory != nil && *tx.Subcategory != "" {
+		label += "/" + *tx.Subcategory
+	}
+	return label
+}
@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
+)
+
+// Rows a deleted rule categorized lose that category; rows categorized by
+// another stage keep theirs
+func TestReclassifyAfterRuleRemoved(t *testing.T) {
+	db.Instance = db.GetMemoryDB()
+	userID := uuid.New()
+	rule := models.ClassificationRule{
+		ID: uuid.New(), UserID: userID, Name: "bar de pepe", Priority: 1,
+		Conditions: models.RuleConditions{Contains: []string{"pepe"}},
+		Category:   "salud", Subcategory: "farmacia", CreatedAt: time.Now(),
+	}
+	if err := db.GetDB().CreateClassificationRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := classifier.ForUser(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parserCat := "ingresos"
+	labelled := models.Transaction{
+		ID: "labelled", UserID: userID, Source: "galicia", Account: "cuenta_corriente",
+		Date: "2024-05-02", Amount: money.Amount(-420000), Currency: "ARS", Description: "LA ESQUINA DE PEPE",
+	}
+	if !rules.Classify(&labelled) {
+		t.Fatal("rule did not match")
+	}
+	parser := models.Transaction{
+		ID: "parser", UserID: userID, Source: "deel", Account: "balance_usd",
+		Date: "2024-05-03", Amount: money.Amount(150000), Currency: "USD", Description: "Acme Corp PEPE",
+		Category: &parserCat, Provenance: classifier.Provenance(classifier.StageParser, "deel type=client_payment"),
+	}
+	if err := db.GetDB().UpsertTransactions([]models.Transaction{labelled, parser}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.GetDB().DeleteClassificationRule(userID, rule.ID); err != nil {
+		t.Fatal(err)
+	}
+	report, err := Reclassify(userID, ReclassifyFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Examined != 2 || report.Changed != 1 {
+		t.Errorf("report = %+v, want 2 examined and 1 changed", report)
+	}
+	if len(report.Moves) != 1 || report.Moves[0] != (CategoryMove{From: "salud/farmacia", To: "", Count: 1}) {
+		t.Errorf("moves = %+v, want salud/farmacia to uncategorized", report.Moves)
+	}
+
+	got, _ := db.GetDB().GetTransaction(userID, labelled.ID)
+	if got.Category != nil || got.Subcategory != nil || got.Provenance != nil {
+		t.Errorf("labelled row = %v/%v %+v, want uncategorized", got.Category, got.Subcategory, got.Provenance)
+	}
+	got, _ = db.GetDB().GetTransaction(userID, parser.ID)
+	if got.Category == nil || *got.Category != parserCat || got.Provenance.Stage != classifier.StageParser {
+		t.Errorf("parser row = %v %+v, want %s from the parser", got.Category, got.Provenance, parserCat)
+	}
+}
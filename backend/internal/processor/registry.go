@@ -0,0 +1,169 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/common"
+	"github.com/juank/finance-ai/backend/internal/processor/parsers"
+)
+
+// MinConfidence is the lowest detection score accepted as a match
+const MinConfidence = 0.5
+
+// ParserInfo describes a statement parser known to the registry
+type ParserInfo struct {
+	ID          string                                `json:"id"`
+	Bank        string                                `json:"bank"`
+	AccountType string                                `json:"account_type"`
+	Extensions  []string                              `json:"extensions"`
+	New         func() common.Normalizer              `json:"-"`
+	Detect      func(*common.Sample) common.Detection `json:"-"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]ParserInfo)
+)
+
+// Register makes a parser available for detection, listing and forced uploads.
+// It panics if the ID is empty or already registered.
+func Register(info ParserInfo) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if info.ID == "" || info.New == nil {
+		panic("processor: Register parser without ID or constructor")
+	}
+	if _, dup := registry[info.ID]; dup {
+		panic("processor: Register called twice for parser " + info.ID)
+	}
+	registry[info.ID] = info
+}
+
+// Parsers returns every registered parser sorted by ID
+func Parsers() []ParserInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	list := make([]ParserInfo, 0, len(registry))
+	for _, info := range registry {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// LookupParser returns the registered parser with the given ID
+func LookupParser(id string) (ParserInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info, ok := registry[id]
+	return info, ok
+}
+
+// DetectedParser is the parser chosen for a file and why it won
+type DetectedParser struct {
+	ID        string            `json:"id"`
+	Bank      string            `json:"bank"`
+	Detection common.Detection  `json:"detection"`
+	Parser    common.Normalizer `json:"-"`
+}
+
+// ResolveParser returns the parser forced by parserID, or the best content match when it is empty
+func ResolveParser(filePath, parserID string) (*DetectedParser, error) {
+	if parserID != "" {
+		info, ok := LookupParser(parserID)
+		if !ok {
+			return nil, fmt.Errorf("unknown parser %q", parserID)
+		}
+		return &DetectedParser{
+			ID:        info.ID,
+			Bank:      info.Bank,
+			Detection: common.Detection{Confidence: 1, Reason: "parser forced by request"},
+			Parser:    info.New(),
+		}, nil
+	}
+	return DetectParser(filePath)
+}
+
+// ProfileParser wraps a user's CSV import profile as the chosen parser
+func ProfileParser(profile models.ImportProfile) *DetectedParser {
+	return &DetectedParser{
+		ID:        "csv_profile",
+		Bank:      profile.Name,
+		Detection: common.Detection{Confidence: 1, Reason: fmt.Sprintf("import profile %q selected by request", profile.Name)},
+		Parser:    &parsers.ProfileCSVParser{Profile: profile},
+	}
+}
+
+// DetectParser sniffs the file content and returns the parser with the highest confidence
+func DetectParser(filePath string) (*DetectedParser, error) {
+	sample, err := parsers.LoadSample(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *DetectedParser
+	for _, info := range Parsers() {
+		if info.Detect == nil {
+			continue
+		}
+		d := info.Detect(sample)
+		if best == nil || d.Confidence > best.Detection.Confidence {
+			best = &DetectedParser{ID: info.ID, Bank: info.Bank, Detection: d}
+		}
+	}
+
+	if best == nil || best.Detection.Confidence < MinConfidence {
+		return nil, fmt.Errorf("no parser recognised this %s file", sample.Kind)
+	}
+	info, _ := LookupParser(best.ID)
+	best.Parser = info.New()
+	return best, nil
+}
+
+func init() {
+	Register(ParserInfo{
+		ID: "brubank_pdf", Bank: "Brubank", AccountType: "caja_ahorro_pesos", Extensions: []string{".pdf"},
+		New:    func() common.Normalizer { return &parsers.BrubankPDFParser{} },
+		Detect: (&parsers.BrubankPDFParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "santander_visa_pdf", Bank: "Santander", AccountType: "credito_visa", Extensions: []string{".pdf"},
+		New:    func() common.Normalizer { return &parsers.SantanderVisaPDFParser{} },
+		Detect: (&parsers.SantanderVisaPDFParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "santander_xlsx", Bank: "Santander", AccountType: "caja_ahorro_pesos", Extensions: []string{".xlsx"},
+		New:    func() common.Normalizer { return &parsers.SantanderXLSXParser{} },
+		Detect: (&parsers.SantanderXLSXParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "mercadopago_csv", Bank: "MercadoPago", AccountType: "cuenta_digital", Extensions: []string{".csv"},
+		New:    func() common.Normalizer { return &parsers.MercadoPagoParser{} },
+		Detect: (&parsers.MercadoPagoParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "deel_csv", Bank: "Deel", AccountType: "balance_usd", Extensions: []string{".csv"},
+		New:    func() common.Normalizer { return &parsers.DeelParser{} },
+		Detect: (&parsers.DeelParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "ofx", Bank: "OFX/QFX", AccountType: "any", Extensions: []string{".ofx", ".qfx"},
+		New:    func() common.Normalizer { return &parsers.OFXParser{} },
+		Detect: (&parsers.OFXParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "camt", Bank: "ISO 20022 camt.053/camt.052", AccountType: "any", Extensions: []string{".xml"},
+		New:    func() common.Normalizer { return &parsers.CamtParser{} },
+		Detect: (&parsers.CamtParser{}).Detect,
+	})
+	Register(ParserInfo{
+		ID: "mt940", Bank: "SWIFT MT940", AccountType: "any", Extensions: []string{".sta", ".mt940", ".940", ".txt"},
+		New:    func() common.Normalizer { return &parsers.MT940Parser{} },
+		Detect: (&parsers.MT940Parser{}).Detect,
+	})
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/fx"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
+)
+
+var (
+	// ErrInvalidPair is returned when the debit side is not negative or the credit side not positive
+	ErrInvalidPair = errors.New("debit must be an outgoing and credit an incoming transaction")
+	// ErrAlreadyLinked is returned when either side already belongs to a transfer link
+	ErrAlreadyLinked = errors.New("transaction is already linked to another transfer")
+)
+
+// LinkTransfer manually pairs a debit and a credit as an internal transfer, or
+// as a card payment when either side is one.
+// The link records the score automatic matching would have given the pair,
+// or 0 when it falls outside the tolerances.
+func LinkTransfer(userID uuid.UUID, debitID, creditID string) (models.TransferLink, error) {
+	debit, err := db.GetDB().GetTransaction(userID, debitID)
+	if err != nil {
+		return models.TransferLink{}, err
+	}
+	credit, err := db.GetDB().GetTransaction(userID, creditID)
+	if err != nil {
+		return models.TransferLink{}, err
+	}
+	if debit.Amount >= 0 || credit.Amount <= 0 {
+		return models.TransferLink{}, ErrInvalidPair
+	}
+	for _, l := range db.GetDB().GetTransferLinks(userID) {
+		if l.Method == models.LinkRejected {
+			continue
+		}
+		if l.DebitID == debitID || l.CreditID == debitID || l.DebitID == creditID || l.CreditID == creditID {
+			return models.TransferLink{}, ErrAlreadyLinked
+		}
+	}
+
+	link, ok := MatchPair(debit, credit, MatchOptionsFor(userID), fx.Default())
+	if !ok {
+		link.Score = 0
+	}
+	link.ID = uuid.New()
+	link.UserID = userID
+	link.Method = models.LinkManual
+	link.CreatedAt = time.Now()
+	if err := db.GetDB().CreateTransferLink(link); err != nil {
+		return models.TransferLink{}, err
+	}
+
+	markLinked(&debit, link.Kind, "linked manually to "+credit.ID)
+	markLinked(&credit, link.Kind, "linked manually to "+debit.ID)
+	return link, db.GetDB().UpsertTransactions([]models.Transaction{debit, credit})
+}
+
+// UnlinkTransfer removes a transfer link. The link is kept as rejected so
+// automatic matching does not pair the same transactions again. Both sides get
+// back the classification they had before being linked and go through the
+// import pipeline again: the user's rules, then the learned model.
+func UnlinkTransfer(userID, linkID uuid.UUID) error {
+	link, err := db.GetDB().GetTransferLink(userID, linkID)
+	if err != nil {
+		return err
+	}
+	if link.Method == models.LinkRejected {
+		return db.ErrNotFound
+	}
+	if err := db.GetDB().RejectTransferLink(userID, linkID); err != nil {
+		return err
+	}
+
+	rules, err := classifier.ForUser(userID)
+	if err != nil {
+		return err
+	}
+	model := classifier.Train(db.GetDB().GetTransactions(userID))
+	var restored []models.Transaction
+	for _, id := range []string{link.DebitID, link.CreditID} {
+		tx, err := db.GetDB().GetTransaction(userID, id)
+		if err != nil {
+			continue
+		}
+		unmarkLinked(&tx)
+		if !rules.Classify(&tx) {
+			model.Fallback(&tx)
+		}
+		restored = append(restored, tx)
+	}
+	return db.GetDB().UpsertTransactions(restored)
+}
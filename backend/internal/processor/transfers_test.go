@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/juank/finance-ai/backend/internal/db"
+	"github.com/juank/finance-ai/backend/internal/models"
+	"github.com/juank/finance-ai/backend/internal/money"
+	"github.com/juank/finance-ai/backend/internal/processor/classifier"
+)
+
+func TestUnlinkRestoresParserCategory(t *testing.T) {
+	db.Instance = db.GetMemoryDB()
+	userID := uuid.New()
+	cat, sub := "ingresos", "sueldo"
+	debit := models.Transaction{
+		ID: "debit", UserID: userID, Source: "galicia", Account: "cuenta_corriente",
+		Date: "2024-03-05", Amount: money.Amount(-150000), Currency: "USD", Description: "TRANSFERENCIA A TERCEROS",
+	}
+	credit := models.Transaction{
+		ID: "credit", UserID: userID, Source: "deel", Account: "balance_usd",
+		Date: "2024-03-05", Amount: money.Amount(150000), Currency: "USD", Description: "Acme Corp",
+		Category: &cat, Subcategory: &sub,
+		Provenance: classifier.Provenance(classifier.StageParser, "deel type=client_payment"),
+	}
+	if err := db.GetDB().UpsertTransactions([]models.Transaction{debit, credit}); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkTransfer(userID, debit.ID, credit.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked, _ := db.GetDB().GetTransaction(userID, credit.ID)
+	if !linked.Neutralized || *linked.Category != "transferencia_interna" {
+		t.Fatalf("linked credit = %v %v, want neutralized transferencia_interna", linked.Neutralized, *linked.Category)
+	}
+
+	if err := UnlinkTransfer(userID, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := db.GetDB().GetTransaction(userID, credit.ID)
+	if got.Neutralized {
+		t.Error("credit still neutralized after unlinking")
+	}
+	if got.Category == nil || *got.Category != cat || got.Subcategory == nil || *got.Subcategory != sub {
+		t.Errorf("credit category = %v/%v, want %s/%s", got.Category, got.Subcategory, cat, sub)
+	}
+	if got.Provenance == nil || got.Provenance.Stage != classifier.StageParser {
+		t.Errorf("credit provenance = %+v, want stage %s", got.Provenance, classifier.StageParser)
+	}
+
+	gotDebit, _ := db.GetDB().GetTransaction(userID, debit.ID)
+	if gotDebit.Neutralized || gotDebit.Category != nil || gotDebit.Provenance != nil {
+		t.Errorf("debit = %v %v %+v, want the uncategorized row it was", gotDebit.Neutralized, gotDebit.Category, gotDebit.Provenance)
+	}
+}
@@ -1,9 +1,30 @@
 "use client";
 
-import { useState } from "react";
+import { useEffect, useState } from "react";
+
+type ParserInfo = {
+    id: string;
+    bank: string;
+    account_type: string;
+    extensions: string[];
+};
 
 export default function UploadButton() {
     const [uploading, setUploading] = useState(false);
+    const [parsers, setParsers] = useState<ParserInfo[]>([]);
+    const [parserId, setParserId] = useState("");
+
+    useEffect(() => {
+        const token = localStorage.getItem("token");
+        fetch("http://localhost:8080/api/parsers", {
+            headers: {
+                "Authorization": `Bearer ${token}`
+            },
+        })
+            .then((res) => (res.ok ? res.json() : []))
+            .then(setParsers)
+            .catch(() => setParsers([]));
+    }, []);
 
     const handleFileChange = async (e: React.ChangeEvent<HTMLInputElement>) => {
         const file = e.target.files?.[0];
@@ -12,6 +33,9 @@ export default function UploadButton() {
         setUploading(true);
         const formData = new FormData();
         formData.append("file", file);
+        if (parserId) {
+            formData.append("parser", parserId);
+        }
 
         const token = localStorage.getItem("token");
         try {
@@ -35,7 +59,20 @@ export default function UploadButton() {
     };
 
     return (
-        <div className="relative">
+        <div className="relative flex items-center gap-3">
+            <select
+                value={parserId}
+                onChange={(e) => setParserId(e.target.value)}
+                disabled={uploading}
+                className="bg-white/5 rounded-xl px-4 py-3 text-sm text-gray-300"
+            >
+                <option value="">Auto-detect bank</option>
+                {parsers.map((p) => (
+                    <option key={p.id} value={p.id}>
+                        {p.bank} ({p.account_type}, {p.extensions.join(" ")})
+                    </option>
+                ))}
+            </select>
             <input
                 type="file"
                 id="file-upload"
@@ -7,14 +7,14 @@ REQUIRED_FIELDS = {
     "source": str,
     "account": str,
     "date": str,
-    "amount": (int, float),
+    "amount": str,
     "currency": str,
     "description": str,
     "direction": str,
     "merchant": (str, type(None)),
     "category": (str, type(None)),
     "subcategory": (str, type(None)),
-    "balance": (int, float, type(None)),
+    "balance": (str, type(None)),
     "is_transfer": bool,
     "is_fee": bool,
     "is_tax": bool
@@ -23,6 +23,9 @@ REQUIRED_FIELDS = {
 def validate_iso_date(date_str):
     return bool(re.match(r'^\d{4}-\d{2}-\d{2}$', date_str))
 
+def validate_amount(amount_str):
+    return bool(re.match(r'^-?\d+\.\d{2}$', amount_str))
+
 def validate_transaction(tx, index, file_path):
     errors = []
     
@@ -40,6 +43,10 @@ def validate_transaction(tx, index, file_path):
     if "date" in tx and not validate_iso_date(tx["date"]):
         errors.append(f"Invalid date format: {tx['date']} (expected YYYY-MM-DD)")
         
+    for field in ("amount", "balance"):
+        if isinstance(tx.get(field), str) and not validate_amount(tx[field]):
+            errors.append(f"Invalid {field} format: {tx[field]} (expected decimal string like -1234.56)")
+
     if "direction" in tx and tx["direction"] not in ["debit", "credit"]:
         errors.append(f"Invalid direction: {tx['direction']} (expected debit or credit)")
         